@@ -0,0 +1,162 @@
+/*
+  - Package cli
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: config.go
+    @Date: 2025/6/17 上午10:15*
+*/
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"gxx/pkg/runner"
+	"gxx/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// profiles 内置扫描预设，优先级低于配置文件与命令行参数
+var profiles = map[string]func(opt *types.CmdOptions){
+	// fast 高并发、短超时、不重试，适合大批量资产快速识别
+	"fast": func(opt *types.CmdOptions) {
+		opt.Threads = 50
+		opt.RuleThreads = 1000
+		opt.Timeout = 2
+		opt.Retries = 1
+		opt.Http.MaxBody = 256 * 1024
+		opt.Http.MaxRedirects = 3
+	},
+	// thorough 长超时、多重试、读取更大的响应体，适合少量重点目标
+	"thorough": func(opt *types.CmdOptions) {
+		opt.Threads = 10
+		opt.RuleThreads = 500
+		opt.Timeout = 10
+		opt.Retries = 3
+		opt.Http.MaxBody = 2 * 1024 * 1024
+		opt.Http.MaxRedirects = 10
+	},
+	// stealth 单线程、限制单主机并发与速率，降低对目标的请求压力
+	"stealth": func(opt *types.CmdOptions) {
+		opt.Threads = 1
+		opt.RuleThreads = runner.MinRuleWorkers
+		opt.Timeout = 10
+		opt.Retries = 1
+		opt.HostRate = 5
+		opt.HostConcurrency = 2
+	},
+}
+
+// profileNames 返回内置预设名称列表
+func profileNames() string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "、")
+}
+
+// applyProfile 应用内置扫描预设
+func applyProfile(options *types.CmdOptions, name string) error {
+	apply, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("未知的扫描预设: %s，可选：%s", name, profileNames())
+	}
+	apply(options)
+	return nil
+}
+
+// isTomlFile 根据扩展名判断是否为TOML格式
+func isTomlFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".toml")
+}
+
+// unmarshalConfig 按文件格式解析配置，仅覆盖配置文件中出现的字段
+func unmarshalConfig(path string, data []byte, v any) error {
+	if isTomlFile(path) {
+		return toml.Unmarshal(data, v)
+	}
+	return yaml.Unmarshal(data, v)
+}
+
+// loadConfigFile 依次应用扫描预设与配置文件：内置默认值 < 扫描预设 < 配置文件
+// profile 为命令行指定的预设，为空时使用配置文件中的 profile
+func loadConfigFile(options *types.CmdOptions, path, profile string) error {
+	var data []byte
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return fmt.Errorf("读取配置文件失败: %v", err)
+		}
+		if profile == "" {
+			var fileProfile struct {
+				Profile string `yaml:"profile" toml:"profile"`
+			}
+			if err := unmarshalConfig(path, data, &fileProfile); err != nil {
+				return fmt.Errorf("解析配置文件失败: %v", err)
+			}
+			profile = fileProfile.Profile
+		}
+	}
+	if profile != "" {
+		if err := applyProfile(options, profile); err != nil {
+			return err
+		}
+	}
+	if data != nil {
+		if err := unmarshalConfig(path, data, options); err != nil {
+			return fmt.Errorf("解析配置文件失败: %v", err)
+		}
+	}
+	if profile != "" {
+		options.Profile = profile
+	}
+	return nil
+}
+
+// dumpConfig 输出合并后的配置，配置文件为TOML时以TOML格式输出，否则输出YAML
+func dumpConfig(options *types.CmdOptions) (string, error) {
+	if isTomlFile(options.Config) {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(options); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	data, err := yaml.Marshal(options)
+	return string(data), err
+}
+
+// argValue 在解析命令行参数前读取指定参数的值，支持 -name value、--name value 与 -name=value
+func argValue(args []string, name string) string {
+	for i, arg := range args {
+		key := strings.TrimLeft(arg, "-")
+		if key == arg {
+			continue
+		}
+		if key == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(key, name+"=") {
+			return strings.TrimPrefix(key, name+"=")
+		}
+	}
+	return ""
+}
+
+// DumpConfigRequested 命令行是否指定了 -dump-config，用于输出配置时不显示banner
+func DumpConfigRequested() bool {
+	for _, arg := range os.Args[1:] {
+		key := strings.TrimLeft(arg, "-")
+		if key != arg && (key == "dump-config" || key == "dump-config=true") {
+			return true
+		}
+	}
+	return false
+}
@@ -9,7 +9,12 @@ package cli
 
 import (
 	"fmt"
+	"gxx/pkg/finger"
+	"gxx/pkg/network"
+	"gxx/pkg/runner"
 	"gxx/types"
+	"gxx/utils/common"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -19,10 +24,15 @@ import (
 // NewCmdOptions 创建并解析命令行选项
 func NewCmdOptions() (*types.CmdOptions, error) {
 	options := &types.CmdOptions{}
+	options.Http.MaxBody = network.DefaultMaxBody
 	flagSet := goflags.NewFlagSet()
 	flagSet.CreateGroup("input", "目标",
-		flagSet.StringSliceVarP(&options.Target, "url", "u", nil, "要扫描的目标URL/主机", goflags.NormalizedStringSliceOptions),
-		flagSet.StringVarP(&options.TargetsFile, "file", "f", "", "要扫描的目标URL/主机列表（每行一个）"),
+		flagSet.StringSliceVarP(&options.Target, "url", "u", nil, "要扫描的目标URL/主机，支持CIDR、IP段（10.0.0.1-10.0.0.50）、端口范围（host:8000-8010）与 @文件 引用", goflags.NormalizedOriginalStringSliceOptions),
+		flagSet.StringVarP(&options.TargetsFile, "file", "f", "", "要扫描的目标URL/主机列表（每行一个，格式同 -url，扫描时逐行读取），- 表示标准输入"),
+		flagSet.IntVar(&options.DedupSize, "dedup-size", runner.DefaultDedupSize, "目标去重的预计目标数量，按此大小分配固定内存的布隆过滤器（误判率百万分之一），0为不去重"),
+		flagSet.StringSliceVarP(&options.ExcludeTargets, "exclude-targets", "et", nil, "排除的目标（逗号分隔或文件），支持IP、CIDR、IP段与主机名，按主机排除", goflags.FileNormalizedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.VHosts, "vhosts", "vh", nil, "虚拟主机列表（逗号分隔或文件），对每个目标IP逐一使用虚拟主机作为Host与SNI识别", goflags.FileNormalizedStringSliceOptions),
+		flagSet.StringVar(&options.Ports, "ports", "", "端口扫描的端口列表，如 top100,8000-9000，设置后对主机与CIDR目标先扫描开放端口再识别HTTP(S)、TLS或TCP服务"),
 		flagSet.IntVarP(&options.Threads, "threads", "t", 5, "并发线程数"),
 		flagSet.IntVarP(&options.RuleThreads, "rulethreads", "rt", 200, "指纹规则并发线程数，最大50000"),
 	)
@@ -31,19 +41,88 @@ func NewCmdOptions() (*types.CmdOptions, error) {
 		flagSet.BoolVar(&options.JSONOutput, "json", false, "使用JSON格式输出结果到文件（默认关闭）"),
 		flagSet.StringVar(&options.SockOutput, "sock", "", "socket文件输出路径，启用后以JSON格式输出到socket文件"),
 	)
+	flagSet.CreateGroup("ratelimit", "速率限制",
+		flagSet.IntVarP(&options.RateLimit, "rate-limit", "rl", 0, "全局每秒最大请求数，0为不限制"),
+		flagSet.IntVarP(&options.HostRate, "host-rate", "hr", 0, "单个主机每秒最大请求数，0为不限制"),
+		flagSet.IntVarP(&options.HostConcurrency, "host-concurrency", "hc", 50, "单个主机最大并发请求数，0为不限制"),
+	)
+	flagSet.CreateGroup("http", "HTTP",
+		flagSet.BoolVar(&options.Http.H2C, "h2c", false, "探测HTTP目标是否支持h2c（HTTP/2明文），支持时使用h2c通信"),
+		flagSet.BoolVar(&options.Http.DisableHTTP2, "disable-http2", false, "禁用HTTPS目标的HTTP/2协商，仅使用HTTP/1.1"),
+		flagSet.BoolVar(&options.Http.DisableKeepAlive, "disable-keepalive", false, "禁用连接复用，每个请求使用新连接"),
+		flagSet.Var(byteSizeValue{&options.Http.MaxBody}, "max-body", "响应体读取上限（解压后），支持字节数或带单位的大小，如 512KB、2MB，超过部分被截断（response.truncated 为true）"),
+		flagSet.StringSliceVarP(&options.Http.Headers, "header", "H", nil, "全局请求头，格式 \"Name: value\"，可指定多个", goflags.StringSliceOptions),
+		flagSet.StringVar(&options.Http.Cookie, "cookie", "", "全局Cookie，如 \"token=xxx; uid=1\""),
+		flagSet.StringVar(&options.Http.HeaderProfile, "header-profile", "default", "请求头预设：default（随机UA）、chrome、curl、none（不添加请求头）"),
+		flagSet.BoolVar(&options.Http.NoSpoof, "no-spoof", false, "禁用随机X-Forwarded-For与随机Cookie"),
+		flagSet.StringVarP(&options.Http.TLSFingerprint, "tls-fingerprint", "ja3", "go", "TLS客户端指纹（JA3）：go（标准库）、chrome、firefox、safari、randomized（每个连接随机）"),
+	)
+	flagSet.CreateGroup("dns", "DNS",
+		flagSet.StringSliceVar(&options.DNS.Resolve, "resolve", nil, "静态主机映射，格式 host:ip（逗号分隔或文件），优先于DNS解析", goflags.FileNormalizedStringSliceOptions),
+		flagSet.StringSliceVar(&options.DNS.Resolvers, "resolver", nil, "自定义DNS服务器列表（逗号分隔或文件），格式 ip、ip:port、udp://ip:port、tcp://ip:port，按顺序查询", goflags.FileNormalizedStringSliceOptions),
+		flagSet.IntVar(&options.DNS.Timeout, "resolver-timeout", 3, "单个DNS服务器的查询超时时间（秒）"),
+	)
+	flagSet.CreateGroup("reverse", "反连",
+		flagSet.StringVar(&options.ReverseConfig, "reverse-config", "", "反连平台配置文件（YAML），支持ceye、interactsh、http、local"),
+		flagSet.StringVar(&options.OOBDns, "oob-dns", "", "内置反连服务DNS监听地址（UDP），如 0.0.0.0:53"),
+		flagSet.StringVar(&options.OOBHttp, "oob-http", "", "内置反连服务HTTP监听地址，如 0.0.0.0:8088"),
+		flagSet.StringVar(&options.OOBLdap, "oob-ldap", "", "内置反连服务LDAP监听地址，如 0.0.0.0:1389"),
+		flagSet.StringVar(&options.OOBDomain, "oob-domain", "", "内置反连服务DNS根域名（默认 gxx.local）"),
+		flagSet.StringVar(&options.OOBIP, "oob-ip", "", "内置反连服务对外公布的IP，目标需能访问该IP（默认根据监听地址推断）"),
+	)
+	flagSet.CreateGroup("config", "配置",
+		flagSet.StringVar(&options.Config, "config", "", "配置文件路径（YAML/TOML），命令行参数优先于配置文件"),
+		flagSet.StringVar(&options.Profile, "profile", "", "扫描预设：fast、stealth、thorough"),
+		flagSet.BoolVar(&options.DumpConfig, "dump-config", false, "输出合并后的配置并退出"),
+	)
 	flagSet.CreateGroup("debug", "调试",
 		flagSet.StringVar(&options.Proxy, "proxy", "", "要使用的http/socks5代理列表（逗号分隔或文件输入）"),
+		flagSet.StringVar(&options.ProxyStrategy, "proxy-strategy", "round-robin", "代理选择策略：round-robin（轮询）、random（随机）、sticky（同一目标固定代理）"),
 		flagSet.StringVar(&options.PocOptions.PocYaml, "p", "", "测试单个的yaml文件"),
 		flagSet.StringVar(&options.PocOptions.PocFile, "pf", "", "测试指定目录下面所有的yaml文件"),
+		flagSet.StringVar(&options.PocOptions.NmapProbes, "nmap-probes", "", "nmap-service-probes 文件，将其中的服务探针转换为TCP/UDP指纹一并加载"),
+		flagSet.IntVar(&options.PocOptions.NmapIntensity, "nmap-intensity", finger.DefaultNmapIntensity, "nmap服务探针的探测强度（0-9），只加载 rarity 不大于该值的探针"),
+		flagSet.StringVar(&options.PocOptions.NmapConvert, "nmap-convert", "", "将 -nmap-probes 指定的文件转换为指纹yaml文件保存到该目录后退出"),
 		flagSet.IntVar(&options.Timeout, "timeout", 3, "所有请求的超时时间（秒），默认3秒"),
+		flagSet.IntVar(&options.Retries, "retries", network.DefaultRetryAttempts, "请求的最大尝试次数（含首次请求），1为不重试，只重试DNS解析失败、连接被拒绝、连接重置、超时、TLS握手失败与5xx响应"),
+		flagSet.StringVar(&options.RetryDelay, "retry-delay", network.DefaultRetryBaseDelay.String(), "首次重试的退避时间，之后每次翻倍（最大10s）并加入随机抖动"),
 		flagSet.BoolVar(&options.Debug, "debug", false, "是否开启debug模式，默认关闭"),
 		flagSet.BoolVar(&options.NoFileLog, "no-file-log", false, "禁用文件日志记录，仅输出到控制台"),
 	)
 
+	// 在解析命令行参数前应用扫描预设与配置文件，使命令行参数优先
+	args := os.Args[1:]
+	if err := loadConfigFile(options, argValue(args, "config"), argValue(args, "profile")); err != nil {
+		return options, err
+	}
+	// 命令行指定目标时替换配置文件中的目标，而不是追加
+	fileTargets := options.Target
+	options.Target = nil
+
 	// 实例化操作
 	if err := flagSet.Parse(); err != nil {
 		return options, fmt.Errorf("无法解析标志: %s", err)
 	}
+	if len(options.Target) == 0 {
+		options.Target = fileTargets
+	}
+
+	// 输出合并后的配置
+	if options.DumpConfig {
+		data, err := dumpConfig(options)
+		if err != nil {
+			return options, fmt.Errorf("输出配置失败: %v", err)
+		}
+		fmt.Print(data)
+		os.Exit(0)
+	}
+	// 转换nmap服务探针
+	if options.PocOptions.NmapConvert != "" {
+		if err := convertNmapProbes(options); err != nil {
+			return options, err
+		}
+		os.Exit(0)
+	}
 	// 验证必参数是否传入
 	if err := verifyOptions(options); err != nil {
 		return options, err
@@ -52,6 +131,34 @@ func NewCmdOptions() (*types.CmdOptions, error) {
 	return options, nil
 }
 
+// byteSizeValue 字节数类型的命令行参数，支持字节数或带单位的大小，如 512KB、2MB
+type byteSizeValue struct {
+	size *int64
+}
+
+func (v byteSizeValue) String() string {
+	if v.size == nil {
+		return ""
+	}
+	switch size := *v.size; {
+	case size > 0 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size > 0 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", size>>10)
+	default:
+		return fmt.Sprintf("%d", size)
+	}
+}
+
+func (v byteSizeValue) Set(value string) error {
+	size, err := common.ParseSize(value)
+	if err != nil {
+		return err
+	}
+	*v.size = size
+	return nil
+}
+
 // verifyOptions 验证命令行选项
 func verifyOptions(opt *types.CmdOptions) error {
 	// 使用反射自动序列化命令行选项用于调试
@@ -59,8 +166,28 @@ func verifyOptions(opt *types.CmdOptions) error {
 	//fmt.Println("命令行选项：", optionsStr)
 
 	// 验证目标输入
-	if len(opt.Target) == 0 && opt.TargetsFile == "" {
-		return fmt.Errorf("必须设置 `-url` 或 `-file` 参数指定扫描目标")
+	if len(opt.Target) == 0 && opt.TargetsFile == "" && !common.StdinPiped() {
+		return fmt.Errorf("必须设置 `-url` 或 `-file` 参数指定扫描目标，或通过标准输入传入目标")
+	}
+
+	// 验证去重大小
+	if opt.DedupSize < 0 {
+		return fmt.Errorf("目标去重数量不能为负数")
+	}
+	if opt.Retries < 0 {
+		return fmt.Errorf("最大尝试次数不能为负数")
+	}
+
+	// 验证nmap探测强度
+	if err := verifyNmapIntensity(opt); err != nil {
+		return err
+	}
+
+	// 验证端口列表
+	if opt.Ports != "" {
+		if _, err := network.ParsePorts(opt.Ports); err != nil {
+			return err
+		}
 	}
 
 	// 验证输出文件格式
@@ -102,3 +229,27 @@ func verifyOptions(opt *types.CmdOptions) error {
 
 	return nil
 }
+
+// verifyNmapIntensity 验证nmap服务探针的探测强度
+func verifyNmapIntensity(opt *types.CmdOptions) error {
+	if opt.PocOptions.NmapIntensity < 0 || opt.PocOptions.NmapIntensity > 9 {
+		return fmt.Errorf("nmap探测强度必须在0-9之间")
+	}
+	return nil
+}
+
+// convertNmapProbes 将 -nmap-probes 指定的文件转换为指纹yaml文件保存到 -nmap-convert 目录
+func convertNmapProbes(opt *types.CmdOptions) error {
+	if opt.PocOptions.NmapProbes == "" {
+		return fmt.Errorf("使用 `-nmap-convert` 时必须通过 `-nmap-probes` 指定 nmap-service-probes 文件")
+	}
+	if err := verifyNmapIntensity(opt); err != nil {
+		return err
+	}
+	count, err := finger.ConvertNmapProbes(opt.PocOptions.NmapProbes, opt.PocOptions.NmapConvert, opt.PocOptions.NmapIntensity)
+	if err != nil {
+		return fmt.Errorf("转换nmap服务探针失败: %v", err)
+	}
+	fmt.Printf("[+] 已将 %d 个nmap服务指纹保存到 %s\n", count, opt.PocOptions.NmapConvert)
+	return nil
+}
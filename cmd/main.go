@@ -21,8 +21,10 @@ import (
 )
 
 func main() {
-	// 设置banner以绿色形式显示
-	color.Green(cli.Banner)
+	// 设置banner以绿色形式显示，输出配置时不显示，便于重定向保存
+	if !cli.DumpConfigRequested() {
+		color.Green(cli.Banner)
+	}
 	// 调用构建命令行参数
 	options, err := cli.NewCmdOptions()
 	if err != nil {
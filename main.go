@@ -19,6 +19,7 @@ import (
 
 type BaseInfoType struct {
 	Target     string
+	VHost      string
 	Title      string
 	ServerInfo *types.ServerInfo
 	StatusCode int32
@@ -133,7 +134,7 @@ func GetFingerMatches(targetResult *runner.TargetResult) []*runner.FingerMatch {
 
 // GetBaseInfo 获取目标URL的基础信息（标题、服务器信息和状态码）
 // 参数:
-//   - target: 目标URL
+//   - target: 目标URL，可携带虚拟主机，如 "https://10.0.0.5:8443 | oa.corp.local"
 //   - proxy: HTTP代理地址 (可为空)
 //   - timeout: 超时时间(秒)
 //
@@ -152,6 +153,7 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoType, error) {
 	}
 
 	BaseInfo.Target = Bas.Url
+	BaseInfo.VHost = Bas.VHost
 	BaseInfo.Title = Bas.Title
 	BaseInfo.ServerInfo = Bas.Server
 	BaseInfo.StatusCode = Bas.StatusCode
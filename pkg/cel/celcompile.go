@@ -25,19 +25,29 @@ var NewEnvOptions = []cel.EnvOption{
 		&proto.Request{},
 		&proto.Response{},
 		&proto.Reverse{},
+		&proto.Session{},
+		&proto.CookieType{},
+		&proto.HeaderValues{},
+		&proto.TimingType{},
+		&proto.ConnInfoType{},
+		&proto.TlsType{},
 		StrStrMapType,
 	),
 	cel.Declarations(
 		decls.NewVar("request", decls.NewObjectType("proto.Request")),
 		decls.NewVar("response", decls.NewObjectType("proto.Response")),
+		decls.NewVar("session", decls.NewObjectType("proto.Session")),
+		decls.NewVar("affected", decls.String),
 	),
 }
 
 // ReadCompileOptions 返回 CEL 环境选项（类型、变量、函数实现）
 // 注：不再使用已弃用的 TypeRegistry 组合适配器/提供者，统一依赖 cel.Types 注册类型
 func ReadCompileOptions() []cel.EnvOption {
-	allEnvOptions := make([]cel.EnvOption, 0, len(NewEnvOptions)+len(FunctionEnvOptions))
+	allEnvOptions := make([]cel.EnvOption, 0, len(NewEnvOptions)+len(FunctionEnvOptions)+len(QueryEnvOptions)+len(VersionEnvOptions))
 	allEnvOptions = append(allEnvOptions, NewEnvOptions...)
 	allEnvOptions = append(allEnvOptions, FunctionEnvOptions...)
+	allEnvOptions = append(allEnvOptions, QueryEnvOptions...)
+	allEnvOptions = append(allEnvOptions, VersionEnvOptions...)
 	return allEnvOptions
 }
@@ -13,9 +13,8 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"gxx/pkg/network"
+	"gxx/pkg/reverse"
 	"gxx/utils/common"
-	"gxx/utils/config"
 	"gxx/utils/proto"
 	"math/rand"
 	"net/url"
@@ -446,6 +445,34 @@ var FunctionEnvOptions = []cel.EnvOption{
 			}),
 		),
 	),
+	// bytesubmatch 与 bsubmatch 相同，但按字节逐个匹配，供 nmap 服务探针转换的规则使用
+	cel.Function("bytesubmatch",
+		cel.MemberOverload("string_bytesubmatch_bytes",
+			[]*cel.Type{cel.StringType, cel.BytesType}, cel.MapType(cel.StringType, cel.StringType),
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				resultMap := make(map[string]string)
+				v1, ok := lhs.(types.String)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to bytesubmatch", lhs.Type())
+				}
+				v2, ok := rhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to bytesubmatch", rhs.Type())
+				}
+				re := regexp2.MustCompile(string(v1), regexp2.RE2)
+				if m, _ := re.FindStringMatch(BytesToLatin1(v2)); m != nil {
+					gps := m.Groups()
+					for n, gp := range gps {
+						if n == 0 {
+							continue
+						}
+						resultMap[gp.Name] = gp.String()
+					}
+				}
+				return types.NewStringStringMap(types.DefaultTypeAdapter, resultMap)
+			}),
+		),
+	),
 	// reverse
 	cel.Function("wait",
 		cel.MemberOverload("reverse_wait_int",
@@ -543,50 +570,20 @@ var FunctionEnvOptions = []cel.EnvOption{
 
 // reverseCheck 检查反向连接
 func reverseCheck(r *proto.Reverse, timeout int64) bool {
-	if len(config.ReverseCeyeApiKey) == 0 || len(r.Domain) == 0 {
-		return false
-	}
-
-	time.Sleep(time.Second * time.Duration(timeout))
-
-	sub := strings.Split(r.Domain, ".")[0]
-	urlStr := fmt.Sprintf("http://api.ceye.io/v1/records?token=%s&type=dns&filter=%s", config.ReverseCeyeApiKey, sub)
-
-	resp, err := network.ReverseGet(urlStr)
-	if err != nil {
-		return false
-	}
-
-	if !bytes.Contains(resp, []byte(`"data": []`)) && bytes.Contains(resp, []byte(`{"code": 200`)) { // api返回结果不为空
-		return true
-	}
-
-	if bytes.Contains(resp, []byte(`<title>503`)) { // api返回结果不为空
-		return false
-	}
-
-	return false
+	return reverse.Current().Poll(r, time.Second*time.Duration(timeout))
 }
 
 // jndiCheck 检查 JNDI 连接
-func jndiCheck(reverse *proto.Reverse, timeout int64) bool {
-	if len(config.ReverseJndi) == 0 && len(config.ReverseApiPort) == 0 {
-		return false
-	}
-
-	time.Sleep(time.Second * time.Duration(timeout))
-
-	urlStr := fmt.Sprintf("http://%s:%s/?api=%s", reverse.Url.Domain, config.ReverseApiPort, reverse.Url.Path[1:])
-
-	resp, err := network.ReverseGet(urlStr)
-	if err != nil {
-		return false
-	}
-
-	if strings.Contains(string(resp), "yes") {
+func jndiCheck(r *proto.Reverse, timeout int64) bool {
+	return reverse.JNDI().Poll(r, time.Second*time.Duration(timeout))
+}
 
-		return true
+// BytesToLatin1 将字节流按字节逐个映射为字符（U+0000-U+00FF），使 \xNN 形式的正则可以匹配对应的字节，
+// 正则中的非ASCII字节需要同样映射后才能匹配（如 nmap 服务探针的匹配规则）
+func BytesToLatin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
 	}
-
-	return false
+	return string(runes)
 }
@@ -0,0 +1,40 @@
+/*
+  - Package cel
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: celprogram_test.go
+    @Date: 2025/6/26 下午5:10*
+*/
+package cel
+
+import (
+	"gxx/utils/proto"
+	"testing"
+)
+
+func TestSubmatchBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		raw  []byte
+		want string
+	}{
+		// bsubmatch 按UTF-8文本匹配，响应中夹杂个别非法字节时不影响中文提取
+		{"bsubmatch中文", `"标题：(?P<t>[^<]+)<".bsubmatch(response.raw)["t"]`, []byte("\xff标题：管理后台<"), "管理后台"},
+		{"bsubmatch纯文本", `"Server: (?P<s>\\S+)".bsubmatch(response.raw)["s"]`, []byte("Server: nginx/1.20\r\n"), "nginx/1.20"},
+		// bytesubmatch 按字节匹配，\xNN 匹配对应字节
+		{"bytesubmatch二进制", `"^S\\xf5\\xc6(?P<v>.)".bytesubmatch(response.raw)["v"]`, []byte("S\xf5\xc6\x1a{"), "\x1a"},
+		{"bytesubmatch合法UTF-8", `"^\\xe4\\xb8\\xad(?P<v>\\w+)".bytesubmatch(response.raw)["v"]`, []byte("中abc"), "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewCustomLib().Evaluate(tt.expr, map[string]any{"response": &proto.Response{Raw: tt.raw}})
+			if err != nil {
+				t.Fatalf("表达式执行失败: %v", err)
+			}
+			if got, _ := result.Value().(string); got != tt.want {
+				t.Errorf("提取结果 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
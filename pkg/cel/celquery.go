@@ -0,0 +1,263 @@
+/*
+  - Package cel
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: celquery.go
+    @Date: 2025/6/13 下午2:40*
+*/
+package cel
+
+import (
+	"bytes"
+	"gxx/pkg/network"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/html"
+)
+
+// maxParsedBodies 响应体解析结果缓存的最大条目数
+const maxParsedBodies = 256
+
+// parsedBody 单个响应体的解析结果，按需惰性解析
+type parsedBody struct {
+	body    []byte // 原始响应体，命中缓存时比较内容，避免哈希碰撞时返回其他响应的解析结果
+	used    uint64 // 最近一次使用的序号，缓存已满时淘汰最久未使用的条目
+	once    sync.Once
+	docOnce sync.Once  // HTML文档树在首次使用时解析，不持有全局缓存锁
+	doc     *html.Node // HTML文档树，供css与xpath查询
+	docErr  error
+	isJSON  bool // 响应体是否为合法JSON
+	jsonStr string
+}
+
+// bodyCache 响应体解析结果缓存，键为响应体的哈希值
+var (
+	bodyCache      = make(map[uint64]*parsedBody, maxParsedBodies)
+	bodyCacheTick  uint64 // 缓存使用序号
+	bodyCacheMutex sync.Mutex
+	jsonIndexRegex = regexp.MustCompile(`\[(\d+|'[^']*'|"[^"]*")]`)
+)
+
+// getParsedBody 获取响应体的解析结果，同一响应体只解析一次
+func getParsedBody(body []byte) *parsedBody {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	key := h.Sum64()
+
+	bodyCacheMutex.Lock()
+	bodyCacheTick++
+	pb, ok := bodyCache[key]
+	if !ok || !bytes.Equal(pb.body, body) {
+		// 哈希碰撞时由新的响应体替换旧条目，已取得旧条目的求值不受影响
+		if !ok && len(bodyCache) >= maxParsedBodies {
+			evictParsedBody()
+		}
+		pb = &parsedBody{body: body}
+		bodyCache[key] = pb
+	}
+	pb.used = bodyCacheTick
+	bodyCacheMutex.Unlock()
+
+	pb.once.Do(func() {
+		// 响应体保留原始字节，GBK 等非UTF-8页面按字符集解码后再解析
+		text, _ := network.DecodeText(body, "")
+		if text == "" {
+			text = string(body)
+		}
+		pb.jsonStr = text
+		pb.isJSON = gjson.Valid(pb.jsonStr)
+	})
+	return pb
+}
+
+// evictParsedBody 淘汰最久未使用的解析结果，调用方需持有 bodyCacheMutex
+func evictParsedBody() {
+	var oldestKey uint64
+	oldest := uint64(math.MaxUint64)
+	for key, pb := range bodyCache {
+		if pb.used < oldest {
+			oldest, oldestKey = pb.used, key
+		}
+	}
+	delete(bodyCache, oldestKey)
+}
+
+// htmlDoc 返回HTML文档树，在首次使用时解析，同一响应体只解析一次，不同响应体可并发解析
+func (pb *parsedBody) htmlDoc() (*html.Node, error) {
+	pb.docOnce.Do(func() {
+		pb.doc, pb.docErr = html.Parse(strings.NewReader(pb.jsonStr))
+	})
+	return pb.doc, pb.docErr
+}
+
+// toGJSONPath 将JSONPath（如 $.data.list[0]['name']）转换为gjson路径（data.list.0.name）
+func toGJSONPath(path string) string {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	path = jsonIndexRegex.ReplaceAllStringFunc(path, func(m string) string {
+		return "." + strings.Trim(m[1:len(m)-1], `'"`)
+	})
+	return strings.TrimPrefix(path, ".")
+}
+
+// jsonValue 将gjson结果转换为CEL值，整数形式的数字转换为int
+func jsonValue(result gjson.Result) ref.Val {
+	if !result.Exists() {
+		return types.NullValue
+	}
+	switch result.Type {
+	case gjson.Number:
+		if f := result.Float(); f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return types.Int(int64(f))
+		}
+		return types.Double(result.Float())
+	case gjson.String:
+		return types.String(result.String())
+	case gjson.True, gjson.False:
+		return types.Bool(result.Bool())
+	case gjson.Null:
+		return types.NullValue
+	}
+	return types.DefaultTypeAdapter.NativeToValue(result.Value())
+}
+
+// cssQuery 返回CSS选择器匹配的第一个节点的属性值，attr为空时返回节点文本
+func cssQuery(body []byte, selector, attr string) ref.Val {
+	sel, err := cascadia.Parse(selector)
+	if err != nil {
+		return types.NewErr("invalid css selector '%s': %v", selector, err)
+	}
+	doc, err := getParsedBody(body).htmlDoc()
+	if err != nil {
+		return types.String("")
+	}
+	node := cascadia.Query(doc, sel)
+	if node == nil {
+		return types.String("")
+	}
+	if attr == "" {
+		return types.String(strings.TrimSpace(htmlquery.InnerText(node)))
+	}
+	return types.String(htmlquery.SelectAttr(node, attr))
+}
+
+// xpathQuery 执行XPath表达式，节点集返回第一个节点的文本，其余返回计算结果
+func xpathQuery(body []byte, expr string) ref.Val {
+	exp, err := xpath.Compile(expr)
+	if err != nil {
+		return types.NewErr("invalid xpath '%s': %v", expr, err)
+	}
+	doc, err := getParsedBody(body).htmlDoc()
+	if err != nil {
+		return types.String("")
+	}
+	switch v := exp.Evaluate(htmlquery.CreateXPathNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		if v.MoveNext() {
+			return types.String(strings.TrimSpace(v.Current().Value()))
+		}
+		return types.String("")
+	case float64:
+		if v == math.Trunc(v) {
+			return types.Int(int64(v))
+		}
+		return types.Double(v)
+	case string:
+		return types.String(v)
+	case bool:
+		return types.Bool(v)
+	}
+	return types.String("")
+}
+
+// QueryEnvOptions 响应体结构化查询函数：json/css/xpath，解析结果按响应体缓存
+var QueryEnvOptions = []cel.EnvOption{
+	// response.body.json("$.data.version")
+	cel.Function("json",
+		cel.MemberOverload("bytes_json_string",
+			[]*cel.Type{cel.BytesType, cel.StringType}, cel.DynType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to json", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to json", rhs.Type())
+				}
+				pb := getParsedBody(v1)
+				if !pb.isJSON {
+					return types.NullValue
+				}
+				path := toGJSONPath(string(v2))
+				if path == "" {
+					return jsonValue(gjson.Parse(pb.jsonStr))
+				}
+				return jsonValue(gjson.Get(pb.jsonStr, path))
+			}),
+		),
+	),
+	// response.body.css("meta[name=generator]", "content")
+	cel.Function("css",
+		cel.MemberOverload("bytes_css_string",
+			[]*cel.Type{cel.BytesType, cel.StringType}, cel.StringType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to css", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to css", rhs.Type())
+				}
+				return cssQuery(v1, string(v2), "")
+			}),
+		),
+		cel.MemberOverload("bytes_css_string_string",
+			[]*cel.Type{cel.BytesType, cel.StringType, cel.StringType}, cel.StringType,
+			cel.FunctionBinding(func(values ...ref.Val) ref.Val {
+				v1, ok := values[0].(types.Bytes)
+				if !ok {
+					return types.ValOrErr(values[0], "unexpected type '%v' passed to css", values[0].Type())
+				}
+				v2, ok := values[1].(types.String)
+				if !ok {
+					return types.ValOrErr(values[1], "unexpected type '%v' passed to css", values[1].Type())
+				}
+				v3, ok := values[2].(types.String)
+				if !ok {
+					return types.ValOrErr(values[2], "unexpected type '%v' passed to css", values[2].Type())
+				}
+				return cssQuery(v1, string(v2), string(v3))
+			}),
+		),
+	),
+	// response.body.xpath("//meta[@name='generator']/@content")
+	cel.Function("xpath",
+		cel.MemberOverload("bytes_xpath_string",
+			[]*cel.Type{cel.BytesType, cel.StringType}, cel.DynType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.Bytes)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to xpath", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to xpath", rhs.Type())
+				}
+				return xpathQuery(v1, string(v2))
+			}),
+		),
+	),
+}
@@ -0,0 +1,75 @@
+/*
+  - Package cel
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: celquery_test.go
+    @Date: 2025/6/26 下午7:20*
+*/
+package cel
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+)
+
+// resetBodyCache 清空响应体解析结果缓存
+func resetBodyCache() {
+	bodyCacheMutex.Lock()
+	bodyCache = make(map[uint64]*parsedBody, maxParsedBodies)
+	bodyCacheMutex.Unlock()
+}
+
+func TestParsedBodyCollision(t *testing.T) {
+	resetBodyCache()
+	defer resetBodyCache()
+
+	// 模拟哈希碰撞：缓存中同一键下保存的是另一个响应体的解析结果
+	body := []byte(`<html><title>目标页面</title></html>`)
+	other := getParsedBody([]byte(`{"name":"other"}`))
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	bodyCacheMutex.Lock()
+	bodyCache[h.Sum64()] = other
+	bodyCacheMutex.Unlock()
+
+	pb := getParsedBody(body)
+	if pb == other || pb.isJSON {
+		t.Fatal("哈希碰撞时不应返回其他响应体的解析结果")
+	}
+	if got := cssQuery(body, "title", ""); got.Value() != "目标页面" {
+		t.Errorf("css 查询结果 %v，期望 目标页面", got.Value())
+	}
+	if getParsedBody(body) != pb {
+		t.Error("同一响应体应复用解析结果")
+	}
+}
+
+func TestParsedBodyEviction(t *testing.T) {
+	resetBodyCache()
+	defer resetBodyCache()
+
+	first := getParsedBody([]byte("body-0"))
+	for i := 1; i < maxParsedBodies; i++ {
+		getParsedBody([]byte(fmt.Sprintf("body-%d", i)))
+		// 持续使用第一个响应体，缓存已满时不应被淘汰
+		getParsedBody([]byte("body-0"))
+	}
+	getParsedBody([]byte("body-new"))
+
+	bodyCacheMutex.Lock()
+	size := len(bodyCache)
+	bodyCacheMutex.Unlock()
+	if size != maxParsedBodies {
+		t.Errorf("缓存条目数 %d，期望 %d", size, maxParsedBodies)
+	}
+	if getParsedBody([]byte("body-0")) != first {
+		t.Error("最近使用的解析结果不应被淘汰")
+	}
+	bodyCacheMutex.Lock()
+	size = len(bodyCache)
+	bodyCacheMutex.Unlock()
+	if size != maxParsedBodies {
+		t.Errorf("命中缓存后条目数 %d，期望 %d", size, maxParsedBodies)
+	}
+}
@@ -0,0 +1,380 @@
+/*
+  - Package cel
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: celversion.go
+    @Date: 2025/6/13 下午4:15*
+*/
+package cel
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// SemverType CEL中的版本号类型，通过 semver("1.2.3") 创建，支持比较运算
+var SemverType = cel.OpaqueType("semver")
+
+// semverRuntimeType semver 的运行时类型，标记 Comparer 特性以支持 < <= > >= 运算
+var semverRuntimeType = cel.ObjectType("semver", traits.ComparerType)
+
+var (
+	versionNumRegex   = regexp.MustCompile(`\d+(?:\.\d+)*`)
+	versionSPRegex    = regexp.MustCompile(`(?:sp|service\s*pack)[\s._-]*(\d+)`)
+	versionBuildRegex = regexp.MustCompile(`(?:build|bld|\+)[\s._-]*(\d+)`)
+	versionPreRegex   = regexp.MustCompile(`(?:^|[^a-z])(dev|snapshot|alpha|beta|preview|pre|rc|cr|a|b)[.]?(\d*)(?:[^a-z]|$)`)
+	// versionLetterRegex 紧跟数字部分的单个字母为补丁版本，如 OpenSSL 的 1.0.2k
+	versionLetterRegex = regexp.MustCompile(`^([a-z])(?:[^a-z0-9]|$)`)
+	versionOpRegex     = regexp.MustCompile(`^(>=|<=|!=|==|>|<|=)?\s*(.+)$`)
+)
+
+// preReleaseRank 预发布标识的排序权重，正式版本权重最高
+var preReleaseRank = map[string]int{
+	"dev":      1,
+	"snapshot": 1,
+	"a":        2,
+	"alpha":    2,
+	"b":        3,
+	"beta":     3,
+	"preview":  4,
+	"pre":      4,
+	"rc":       5,
+	"cr":       5,
+}
+
+// releaseRank 正式版本的排序权重
+const releaseRank = 10
+
+// Version 解析后的版本号，兼容厂商常见格式，如 V9.0 SP2、2.4.49-ubuntu、10.0 build 17763
+type Version struct {
+	Original string  // 原始版本字符串
+	Segments []int64 // 数字部分，如 2.4.49 为 [2 4 49]
+	PreRank  int     // 预发布权重，正式版本为 releaseRank
+	PreNum   int64   // 预发布序号，如 rc2 为 2
+	Letter   int64   // 补丁字母序号，如 1.0.2k 为 11，高于不带字母的版本
+	SP       int64   // 服务包序号，如 SP2 为 2
+	Build    int64   // 构建号
+}
+
+// ParseVersion 解析版本字符串，无法识别数字部分时返回错误
+func ParseVersion(s string) (*Version, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	loc := versionNumRegex.FindStringIndex(lower)
+	if loc == nil {
+		return nil, fmt.Errorf("无法解析版本号: %q", s)
+	}
+	v := &Version{Original: s, PreRank: releaseRank}
+	for _, part := range strings.Split(lower[loc[0]:loc[1]], ".") {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无法解析版本号: %q", s)
+		}
+		v.Segments = append(v.Segments, n)
+	}
+	// 数字部分之后的后缀：补丁字母、服务包、构建号、预发布标识，其余后缀（如 -ubuntu）忽略
+	rest := lower[loc[1]:]
+	if m := versionLetterRegex.FindStringSubmatch(rest); m != nil {
+		v.Letter = int64(m[1][0]-'a') + 1
+		rest = rest[1:]
+	}
+	if m := versionSPRegex.FindStringSubmatch(rest); m != nil {
+		v.SP, _ = strconv.ParseInt(m[1], 10, 64)
+		rest = strings.Replace(rest, m[0], " ", 1)
+	}
+	if m := versionBuildRegex.FindStringSubmatch(rest); m != nil {
+		v.Build, _ = strconv.ParseInt(m[1], 10, 64)
+		rest = strings.Replace(rest, m[0], " ", 1)
+	}
+	if m := versionPreRegex.FindStringSubmatchIndex(rest); m != nil && isPreRelease(rest, m) {
+		v.PreRank = preReleaseRank[rest[m[2]:m[3]]]
+		v.PreNum, _ = strconv.ParseInt(rest[m[4]:m[5]], 10, 64)
+	}
+	return v, nil
+}
+
+// isPreRelease 判断匹配到的预发布标识是否有效，单字母的 a/b 只有以 - 或 . 分隔（如 1.0-a）
+// 或后接数字（如 2.0b3）时才视为 alpha/beta，避免将 1.0.2a 等补丁版本误判为预发布版本
+func isPreRelease(rest string, m []int) bool {
+	tag := rest[m[2]:m[3]]
+	if tag != "a" && tag != "b" || m[5] > m[4] {
+		return true
+	}
+	return m[2] > 0 && (rest[m[2]-1] == '-' || rest[m[2]-1] == '.')
+}
+
+// Compare 比较两个版本号，a<b 返回-1，a==b 返回0，a>b 返回1
+func (v *Version) Compare(o *Version) int {
+	n := max(len(v.Segments), len(o.Segments))
+	for i := 0; i < n; i++ {
+		var a, b int64
+		if i < len(v.Segments) {
+			a = v.Segments[i]
+		}
+		if i < len(o.Segments) {
+			b = o.Segments[i]
+		}
+		if c := compareInt(a, b); c != 0 {
+			return c
+		}
+	}
+	if c := compareInt(v.Letter, o.Letter); c != 0 {
+		return c
+	}
+	if c := compareInt(int64(v.PreRank), int64(o.PreRank)); c != 0 {
+		return c
+	}
+	if c := compareInt(v.PreNum, o.PreNum); c != 0 {
+		return c
+	}
+	if c := compareInt(v.SP, o.SP); c != 0 {
+		return c
+	}
+	return compareInt(v.Build, o.Build)
+}
+
+// InRange 判断版本是否在范围内，逗号分隔的条件需同时满足，|| 分隔多个范围，如 ">=2.0,<2.4.1 || =3.0"
+func (v *Version) InRange(constraint string) (bool, error) {
+	for _, group := range strings.Split(constraint, "||") {
+		matched := true
+		empty := true
+		for _, cond := range strings.Split(group, ",") {
+			cond = strings.TrimSpace(cond)
+			if cond == "" {
+				continue
+			}
+			empty = false
+			m := versionOpRegex.FindStringSubmatch(cond)
+			target, err := ParseVersion(m[2])
+			if err != nil {
+				return false, fmt.Errorf("无效的版本范围 %q: %v", constraint, err)
+			}
+			c := v.Compare(target)
+			switch m[1] {
+			case ">=":
+				matched = c >= 0
+			case "<=":
+				matched = c <= 0
+			case ">":
+				matched = c > 0
+			case "<":
+				matched = c < 0
+			case "!=":
+				matched = c != 0
+			default:
+				matched = c == 0
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched && !empty {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// compareInt 比较两个整数
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// semverVal Version 在CEL中的值包装，实现 ref.Val 接口
+type semverVal struct {
+	*Version
+}
+
+// ConvertToNative 转换为Go原生类型
+func (s semverVal) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	switch typeDesc {
+	case reflect.TypeOf(&Version{}):
+		return s.Version, nil
+	case reflect.TypeOf(""):
+		return s.Original, nil
+	}
+	return nil, fmt.Errorf("type conversion error from semver to '%v'", typeDesc)
+}
+
+// ConvertToType 转换为CEL类型，支持转换为字符串
+func (s semverVal) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case SemverType, semverRuntimeType:
+		return s
+	case types.StringType:
+		return types.String(s.Original)
+	case types.TypeType:
+		return SemverType
+	}
+	return types.NewErr("type conversion error from 'semver' to '%s'", typeVal)
+}
+
+// Equal 版本号相等判断，忽略原始写法差异
+func (s semverVal) Equal(other ref.Val) ref.Val {
+	o, ok := other.(semverVal)
+	if !ok {
+		return types.False
+	}
+	return types.Bool(s.Version.Compare(o.Version) == 0)
+}
+
+// Compare 版本号比较，供CEL比较运算符使用
+func (s semverVal) Compare(other ref.Val) ref.Val {
+	o, ok := other.(semverVal)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return types.Int(s.Version.Compare(o.Version))
+}
+
+// Type 返回CEL类型
+func (s semverVal) Type() ref.Type {
+	return semverRuntimeType
+}
+
+// Value 返回原始值
+func (s semverVal) Value() any {
+	return s.Version
+}
+
+// toVersion 将string或semver参数转换为 Version
+func toVersion(val ref.Val, fn string) (*Version, ref.Val) {
+	switch v := val.(type) {
+	case semverVal:
+		return v.Version, nil
+	case types.String:
+		ver, err := ParseVersion(string(v))
+		if err != nil {
+			return nil, types.NewErr("%s: %v", fn, err)
+		}
+		return ver, nil
+	}
+	return nil, types.ValOrErr(val, "unexpected type '%v' passed to %s", val.Type(), fn)
+}
+
+// semverField 生成获取版本号指定段的成员函数
+func semverField(name string, index int) cel.EnvOption {
+	return cel.Function(name,
+		cel.MemberOverload("semver_"+name,
+			[]*cel.Type{SemverType}, cel.IntType,
+			cel.UnaryBinding(func(val ref.Val) ref.Val {
+				v, ok := val.(semverVal)
+				if !ok {
+					return types.ValOrErr(val, "unexpected type '%v' passed to %s", val.Type(), name)
+				}
+				if index < len(v.Segments) {
+					return types.Int(v.Segments[index])
+				}
+				return types.Int(0)
+			}),
+		),
+	)
+}
+
+// VersionEnvOptions 版本号比较相关函数：versionCompare、versionInRange 以及 semver 类型
+var VersionEnvOptions = []cel.EnvOption{
+	// versionCompare("2.4.49-ubuntu", "2.4.50") == -1
+	cel.Function("versionCompare",
+		cel.Overload("versionCompare_string_string",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, errVal := toVersion(lhs, "versionCompare")
+				if errVal != nil {
+					return errVal
+				}
+				v2, errVal := toVersion(rhs, "versionCompare")
+				if errVal != nil {
+					return errVal
+				}
+				return types.Int(v1.Compare(v2))
+			}),
+		),
+	),
+	// versionInRange("V9.0 SP2", ">=9.0,<9.0 SP3")，版本号无法解析时返回false
+	cel.Function("versionInRange",
+		cel.Overload("versionInRange_string_string",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(types.String)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to versionInRange", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to versionInRange", rhs.Type())
+				}
+				ver, err := ParseVersion(string(v1))
+				if err != nil {
+					return types.False
+				}
+				inRange, err := ver.InRange(string(v2))
+				if err != nil {
+					return types.NewErr("versionInRange: %v", err)
+				}
+				return types.Bool(inRange)
+			}),
+		),
+		cel.MemberOverload("semver_versionInRange_string",
+			[]*cel.Type{SemverType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+				v1, ok := lhs.(semverVal)
+				if !ok {
+					return types.ValOrErr(lhs, "unexpected type '%v' passed to versionInRange", lhs.Type())
+				}
+				v2, ok := rhs.(types.String)
+				if !ok {
+					return types.ValOrErr(rhs, "unexpected type '%v' passed to versionInRange", rhs.Type())
+				}
+				inRange, err := v1.InRange(string(v2))
+				if err != nil {
+					return types.NewErr("versionInRange: %v", err)
+				}
+				return types.Bool(inRange)
+			}),
+		),
+	),
+	// semver("2.4.49") < semver("2.4.50")
+	cel.Function("semver",
+		cel.Overload("semver_string",
+			[]*cel.Type{cel.StringType}, SemverType,
+			cel.UnaryBinding(func(val ref.Val) ref.Val {
+				v, errVal := toVersion(val, "semver")
+				if errVal != nil {
+					return errVal
+				}
+				return semverVal{v}
+			}),
+		),
+	),
+	semverField("major", 0),
+	semverField("minor", 1),
+	semverField("patch", 2),
+	// 比较运算由 semverVal.Compare 实现，这里仅声明 semver 类型的重载
+	cel.Function(operators.Less,
+		cel.Overload("less_semver", []*cel.Type{SemverType, SemverType}, cel.BoolType)),
+	cel.Function(operators.LessEquals,
+		cel.Overload("less_equals_semver", []*cel.Type{SemverType, SemverType}, cel.BoolType)),
+	cel.Function(operators.Greater,
+		cel.Overload("greater_semver", []*cel.Type{SemverType, SemverType}, cel.BoolType)),
+	cel.Function(operators.GreaterEquals,
+		cel.Overload("greater_equals_semver", []*cel.Type{SemverType, SemverType}, cel.BoolType)),
+	cel.Function("string",
+		cel.Overload("string_semver", []*cel.Type{SemverType}, cel.StringType,
+			cel.UnaryBinding(func(val ref.Val) ref.Val {
+				return val.ConvertToType(types.StringType)
+			}))),
+}
@@ -0,0 +1,90 @@
+/*
+  - Package cel
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: celversion_test.go
+    @Date: 2025/6/26 下午4:20*
+*/
+package cel
+
+import "testing"
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		// 常见厂商写法
+		{"2.4.49-ubuntu", "2.4.50", -1},
+		{"2.4.49-ubuntu", "2.4.49", 0},
+		{"V9.0 SP2", "9.0 SP3", -1},
+		{"V9.0 SP2", "9.0.1", -1},
+		{"10.0 build 17763", "10.0 build 17134", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+		{"1.0.0-beta", "1.0.0-rc1", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-SNAPSHOT", "1.0.0-alpha", -1},
+		{"1.2", "1.2.0", 0},
+		// OpenSSL 补丁字母版本
+		{"1.0.2a", "1.0.2", 1},
+		{"1.0.2k", "1.0.2", 1},
+		{"1.0.2k", "1.0.2a", 1},
+		{"1.0.2k", "1.0.2k", 0},
+		{"1.0.2u", "1.0.3", -1},
+		{"OpenSSL 1.1.1w", "1.1.1t", 1},
+		{"1.0.2k-fips", "1.0.2k", 0},
+		{"1.0.2k-fips", "1.0.2j", 1},
+		// 单字母 a/b 仅在分隔或后接数字时为预发布版本
+		{"1.0-a", "1.0", -1},
+		{"1.0.b", "1.0-a", 1},
+		{"2.0b3", "2.0", -1},
+		{"2.0a1", "2.0b1", -1},
+		{"2.0b3", "2.0b2", 1},
+	}
+	for _, tt := range tests {
+		a, err := ParseVersion(tt.a)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", tt.a, err)
+		}
+		b, err := ParseVersion(tt.b)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", tt.b, err)
+		}
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("versionCompare(%q, %q) = %d，期望 %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := b.Compare(a); got != -tt.want {
+			t.Errorf("versionCompare(%q, %q) = %d，期望 %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestVersionInRange(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		want                bool
+	}{
+		{"2.4.49", ">=2.4.0,<2.4.51", true},
+		{"2.4.51", ">=2.4.0,<2.4.51", false},
+		{"V9.0 SP2", ">=9.0,<9.0 SP3", true},
+		{"3.0", ">=2.0,<2.4.1 || =3.0", true},
+		{"1.0.2k", ">=1.0.2,<1.0.2l", true},
+		{"1.0.2l", ">=1.0.2,<1.0.2l", false},
+		{"1.0.2", ">1.0.2", false},
+		{"1.0.2a", ">1.0.2", true},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.version)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", tt.version, err)
+		}
+		got, err := v.InRange(tt.constraint)
+		if err != nil {
+			t.Fatalf("versionInRange(%q, %q) 返回错误: %v", tt.version, tt.constraint, err)
+		}
+		if got != tt.want {
+			t.Errorf("versionInRange(%q, %q) = %v，期望 %v", tt.version, tt.constraint, got, tt.want)
+		}
+	}
+}
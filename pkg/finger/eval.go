@@ -12,10 +12,9 @@ import (
 	"github.com/google/cel-go/checker/decls"
 	"gopkg.in/yaml.v2"
 	"gxx/pkg/cel"
+	"gxx/pkg/reverse"
 	"gxx/utils/common"
-	"gxx/utils/config"
 	"gxx/utils/proto"
-	"net/url"
 	"strings"
 	
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
@@ -90,31 +89,14 @@ func SetVariableMap(find string, variableMap map[string]any) string {
 	return find
 }
 
-// newReverse 处理dns反连
+// newReverse 处理dns反连，由当前配置的反连平台生成
 func newReverse() *proto.Reverse {
-	sub := common.RandomString(12)
-	urlStr := fmt.Sprintf("http://%s.%s", sub, config.ReverseCeyeDomain)
-	u, _ := url.Parse(urlStr)
-	return &proto.Reverse{
-		Url:                common.ParseUrl(u),
-		Domain:             u.Hostname(),
-		Ip:                 u.Host,
-		IsDomainNameServer: false,
-	}
+	return reverse.Current().New()
 }
 
-// newJNDI 处理jndi连接
+// newJNDI 处理jndi连接，由当前配置的JNDI反连平台生成
 func newJNDI() *proto.Reverse {
-	randomStr := common.RandomString(22)
-	urlStr := fmt.Sprintf("http://%s:%s/%s", config.ReverseJndi, config.ReverseLdapPort, randomStr)
-	u, _ := url.Parse(urlStr)
-	parseUrl := common.ParseUrl(u)
-	return &proto.Reverse{
-		Url:                parseUrl,
-		Domain:             u.Hostname(),
-		Ip:                 config.ReverseJndi,
-		IsDomainNameServer: false,
-	}
+	return reverse.JNDI().New()
 }
 
 // BatchFuzzSet 批量处理多个Set中的定义变量，优化性能
@@ -15,7 +15,6 @@ import (
 	"gxx/pkg/network"
 	"gxx/utils/common"
 	"gxx/utils/logger"
-	"io"
 	"net/http"
 	"net/url"
 	"path"
@@ -35,6 +34,7 @@ type GetIconHash struct {
 	headers    map[string]string
 	fileHeader []string
 	proxy      string
+	vhost      string // 虚拟主机，icon 与页面位于同一目标地址时使用
 }
 
 // NewGetIconHash 初始化 GetIconHash
@@ -99,13 +99,15 @@ func (g *GetIconHash) hashHTTPURL(iconURL string) int32 {
 	options := network.OptionsRequest{
 		Proxy:              g.proxy,
 		Timeout:            5 * time.Second,
-		Retries:            2,
 		FollowRedirects:    true,
 		InsecureSkipVerify: true,
 		CustomHeaders:      g.headers,
+		VHost:              g.vhost,
 	}
-	// 创建上下文
-	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+	// 等待主机并发槽位后创建上下文，速率限制在实际发送时等待
+	release := network.AcquireHostSlot(iconURL)
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), network.Retry.Budget(options.Timeout))
 	defer cancel()
 
 	// 发送请求
@@ -115,10 +117,10 @@ func (g *GetIconHash) hashHTTPURL(iconURL string) int32 {
 		return 0
 	}
 
-	// 读取响应体（限制最大1MB）
+	// 读取并解压响应体（不超过 -max-body）
 	var bodyBytes []byte
 	if resp.StatusCode == http.StatusOK {
-		bodyBytes, err = io.ReadAll(io.LimitReader(resp.Body, network.MaxDefaultBody))
+		bodyBytes, _, err = network.ReadBody(resp, network.MaxDefaultBody)
 		if err != nil {
 			logger.Debug(fmt.Sprintf("读取响应体失败: %s", err))
 			return 0
@@ -0,0 +1,437 @@
+/*
+  - Package finger
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: nmap.go
+    @Date: 2025/6/22 下午3:20*
+*/
+package finger
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"gxx/pkg/cel"
+	"gxx/utils/logger"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dlclark/regexp2"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultNmapIntensity 默认探测强度，与 nmap --version-intensity 的默认值一致，只导入 rarity 不大于该值的探针
+const DefaultNmapIntensity = 7
+
+// nmapOutputVar 保存匹配分组的变量名，同时作为匹配正则外层命名分组的名称
+const nmapOutputVar = "nmap"
+
+// nmapFieldNames 版本信息模板与提取字段的对应关系
+var nmapFieldNames = map[string]string{
+	"p":   "product",
+	"v":   "version",
+	"i":   "info",
+	"h":   "hostname",
+	"o":   "os",
+	"d":   "device",
+	"cpe": "cpe",
+}
+
+// nmapTemplateRef 版本信息模板中对匹配分组的引用：$1、$P(1)、$SUBST(1,"_",".")、$I(1,">")
+var nmapTemplateRef = regexp.MustCompile(`\$(?:(\d)|P\((\d)\)|SUBST\((\d),"[^"]*","[^"]*"\)|I\((\d),"[<>]"\))`)
+
+// nmapProbe nmap-service-probes 中的一个探针
+type nmapProbe struct {
+	protocol    string // TCP 或 UDP
+	name        string
+	data        []byte
+	rarity      int
+	ports       string
+	sslPorts    string
+	totalWaitMs int
+	matches     []nmapMatch
+}
+
+// nmapMatch 探针下的 match 或 softmatch 规则
+type nmapMatch struct {
+	soft    bool
+	service string
+	pattern string // 转换为 regexp2 语法后的正则，外层为名为 nmap 的命名分组
+	fields  []nmapField
+}
+
+// nmapField 版本信息模板，如 p/OpenSSH/ 对应 product
+type nmapField struct {
+	name     string
+	template string
+}
+
+// ReadNmapProbes 读取 nmap-service-probes 文件并转换为指纹，intensity 为探测强度（0-9），rarity 大于该值的探针不导入
+func ReadNmapProbes(fileName string, intensity int) ([]*Finger, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseNmapProbes(file, intensity)
+}
+
+// ParseNmapProbes 解析 nmap-service-probes 内容并转换为指纹，每条 match/softmatch 规则转换为一个指纹：
+// 探针数据作为 tcp/udp 请求的十六进制 data，匹配正则转换为按字节匹配的 bytesubmatch 表达式，p/、v/、cpe:/ 等版本信息模板转换为 output 中的提取字段
+func ParseNmapProbes(r io.Reader, intensity int) ([]*Finger, error) {
+	var (
+		probes  []*nmapProbe
+		current *nmapProbe
+		lineNo  int
+		skipped int
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		directive, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		if directive == "Probe" {
+			probe, err := parseNmapProbe(rest)
+			if err != nil {
+				return nil, fmt.Errorf("第%d行探针格式错误: %v", lineNo, err)
+			}
+			probes = append(probes, probe)
+			current = probe
+			continue
+		}
+		// Exclude 等全局指令出现在第一个探针之前
+		if current == nil {
+			continue
+		}
+		switch directive {
+		case "match", "softmatch":
+			match, err := parseNmapMatch(rest, directive == "softmatch")
+			if err != nil {
+				skipped++
+				logger.Debug(fmt.Sprintf("跳过第%d行的匹配规则：%v", lineNo, err))
+				continue
+			}
+			current.matches = append(current.matches, match)
+		case "rarity":
+			current.rarity, _ = strconv.Atoi(rest)
+		case "ports":
+			current.ports = rest
+		case "sslports":
+			current.sslPorts = rest
+		case "totalwaitms":
+			current.totalWaitMs, _ = strconv.Atoi(rest)
+		}
+		// fallback、tcpwrappedms 等指令不影响指纹转换
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if skipped > 0 {
+		logger.Warn(fmt.Sprintf("nmap服务探针中有%d条匹配规则无法转换，已跳过", skipped))
+	}
+
+	var fingers []*Finger
+	for _, probe := range probes {
+		if probe.rarity > intensity {
+			continue
+		}
+		for i, match := range probe.matches {
+			fingers = append(fingers, probe.finger(match, i))
+		}
+	}
+	return fingers, nil
+}
+
+// ConvertNmapProbes 将 nmap-service-probes 文件转换为指纹yaml文件保存到 outputDir，返回转换的指纹数量
+func ConvertNmapProbes(fileName, outputDir string, intensity int) (int, error) {
+	fingers, err := ReadNmapProbes(fileName, intensity)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return 0, err
+	}
+	for _, f := range fingers {
+		data, err := yaml.Marshal(f.nmapYaml())
+		if err != nil {
+			return 0, fmt.Errorf("序列化指纹 %s 失败: %v", f.Id, err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, f.Id+".yml"), data, 0644); err != nil {
+			return 0, err
+		}
+	}
+	return len(fingers), nil
+}
+
+// parseNmapProbe 解析 Probe 指令，如 TCP GetRequest q|GET / HTTP/1.0\r\n\r\n|
+func parseNmapProbe(s string) (*nmapProbe, error) {
+	fields := strings.SplitN(s, " ", 3)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("缺少协议、名称或探测数据")
+	}
+	protocol := strings.ToUpper(fields[0])
+	if protocol != "TCP" && protocol != "UDP" {
+		return nil, fmt.Errorf("不支持的协议 %s", fields[0])
+	}
+	if !strings.HasPrefix(fields[2], "q") {
+		return nil, fmt.Errorf("探测数据必须以 q 开头")
+	}
+	value, _, err := cutDelimited(fields[2][1:])
+	if err != nil {
+		return nil, err
+	}
+	return &nmapProbe{protocol: protocol, name: fields[1], data: unescapeNmapData(value)}, nil
+}
+
+// parseNmapMatch 解析 match/softmatch 指令，如 ssh m|^SSH-([\d.]+)-OpenSSH_([\w._-]+)\r?\n| p/OpenSSH/ v/$2/
+func parseNmapMatch(s string, soft bool) (nmapMatch, error) {
+	match := nmapMatch{soft: soft}
+	service, rest, _ := strings.Cut(s, " ")
+	if service == "" || !strings.HasPrefix(rest, "m") {
+		return match, fmt.Errorf("缺少服务名称或匹配正则")
+	}
+	pattern, rest, err := cutDelimited(rest[1:])
+	if err != nil {
+		return match, err
+	}
+	// 正则选项：i 忽略大小写，s 使 . 匹配换行
+	options := ""
+	for rest != "" && rest[0] != ' ' {
+		if rest[0] == 'i' || rest[0] == 's' {
+			options += string(rest[0])
+		}
+		rest = rest[1:]
+	}
+	// 响应按字节映射为字符后匹配，正则中的非ASCII字节同样映射
+	pattern = "(?<" + nmapOutputVar + ">" + cel.BytesToLatin1([]byte(pattern)) + ")"
+	if options != "" {
+		pattern = "(?" + options + ")" + pattern
+	}
+	// 与 bytesubmatch 使用相同的选项校验，避免表达式执行时编译失败
+	if _, err := regexp2.Compile(pattern, regexp2.RE2); err != nil {
+		return match, fmt.Errorf("正则无法编译: %v", err)
+	}
+	fields, err := parseNmapVersionInfo(rest)
+	if err != nil {
+		return match, err
+	}
+	match.service, match.pattern, match.fields = service, pattern, fields
+	return match, nil
+}
+
+// parseNmapVersionInfo 解析版本信息模板，如 p/OpenSSH/ v/$2/ i/protocol $1/ cpe:/a:openbsd:openssh:$2/，多个 cpe 以空格连接
+func parseNmapVersionInfo(s string) ([]nmapField, error) {
+	var fields []nmapField
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		key, template := s[:1], s[1:]
+		if strings.HasPrefix(s, "cpe:") {
+			key, template = "cpe", s[4:]
+		}
+		value, rest, err := cutDelimited(template)
+		if err != nil {
+			return nil, fmt.Errorf("版本信息 %s 格式错误: %v", key, err)
+		}
+		// 跳过模板后的标志，如 cpe 的 a
+		if i := strings.IndexByte(rest, ' '); i >= 0 {
+			s = rest[i:]
+		} else {
+			s = ""
+		}
+		name, ok := nmapFieldNames[key]
+		if !ok {
+			continue
+		}
+		if key == "cpe" {
+			value = "cpe:/" + value
+			if i := len(fields) - 1; i >= 0 && fields[i].name == name {
+				fields[i].template += " " + value
+				continue
+			}
+		}
+		fields = append(fields, nmapField{name: name, template: value})
+	}
+	return fields, nil
+}
+
+// cutDelimited 读取以首字符为分隔符的内容，如 |abc| 返回 abc 与分隔符之后的剩余部分
+func cutDelimited(s string) (string, string, error) {
+	if len(s) < 2 {
+		return "", "", fmt.Errorf("缺少分隔符")
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return "", "", fmt.Errorf("缺少结束分隔符 %c", s[0])
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+// unescapeNmapData 解码探测数据中的转义字符：\\、\0、\a、\b、\f、\n、\r、\t、\v 与 \xHH
+func unescapeNmapData(s string) []byte {
+	data := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			data = append(data, s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case '0':
+			data = append(data, 0)
+		case 'a':
+			data = append(data, '\a')
+		case 'b':
+			data = append(data, '\b')
+		case 'f':
+			data = append(data, '\f')
+		case 'n':
+			data = append(data, '\n')
+		case 'r':
+			data = append(data, '\r')
+		case 't':
+			data = append(data, '\t')
+		case 'v':
+			data = append(data, '\v')
+		case 'x':
+			if i+2 < len(s) {
+				if b, err := hex.DecodeString(s[i+1 : i+3]); err == nil {
+					data = append(data, b[0])
+					i += 2
+					continue
+				}
+			}
+			data = append(data, 'x')
+		default:
+			data = append(data, s[i])
+		}
+	}
+	return data
+}
+
+// nmapTemplateExpr 将版本信息模板转换为CEL表达式，$1 等分组引用替换为 nmap["1"]，分组未匹配时为空字符串，
+// $P()、$SUBST()、$I() 按原始分组内容处理
+func nmapTemplateExpr(template string) string {
+	var parts []string
+	last := 0
+	for _, loc := range nmapTemplateRef.FindAllStringSubmatchIndex(template, -1) {
+		if loc[0] > last {
+			parts = append(parts, strconv.Quote(template[last:loc[0]]))
+		}
+		for g := 2; g < len(loc); g += 2 {
+			if loc[g] >= 0 {
+				group := strconv.Quote(template[loc[g]:loc[g+1]])
+				parts = append(parts, fmt.Sprintf("(%s in %s ? %s[%s] : \"\")", group, nmapOutputVar, nmapOutputVar, group))
+				break
+			}
+		}
+		last = loc[1]
+	}
+	if last < len(template) || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(template[last:]))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// finger 将探针的第 index 条匹配规则转换为指纹
+func (p *nmapProbe) finger(match nmapMatch, index int) *Finger {
+	reqType := strings.ToLower(p.protocol)
+	submatch := strconv.Quote(match.pattern) + ".bytesubmatch(response.raw)"
+
+	name := match.service
+	output := yaml.MapSlice{{Key: nmapOutputVar, Value: submatch}}
+	for _, field := range match.fields {
+		if field.name == "product" && !strings.Contains(field.template, "$") {
+			name = field.template
+		}
+		output = append(output, yaml.MapItem{Key: field.name, Value: nmapTemplateExpr(field.template)})
+	}
+
+	kind := "match"
+	tags := []string{"nmap", reqType, match.service}
+	if match.soft {
+		kind = "softmatch"
+		tags = append(tags, "softmatch")
+	}
+	description := fmt.Sprintf("nmap-service-probes 探针 %s %s 的 %s 规则，服务：%s", p.protocol, p.name, kind, match.service)
+	if p.ports != "" {
+		description += "，常见端口：" + p.ports
+	}
+	if p.sslPorts != "" {
+		description += "，常见TLS端口：" + p.sslPorts
+	}
+
+	return &Finger{
+		Id: nmapFingerID(p.protocol, p.name, match.service, index),
+		Info: Info{
+			Name:        name,
+			Author:      "nmap",
+			Description: description,
+			Tags:        strings.Join(tags, ","),
+		},
+		Rules: RuleMapSlice{{
+			Key: "r0",
+			Value: Rule{
+				Request: RuleRequest{
+					Type:        reqType,
+					Data:        hex.EncodeToString(p.data),
+					DataType:    "hex",
+					ReadTimeout: (p.totalWaitMs + 999) / 1000,
+				},
+				Expression: fmt.Sprintf("%q in %s", nmapOutputVar, submatch),
+				Output:     output,
+			},
+		}},
+		Expression: "r0()",
+	}
+}
+
+// nmapFingerID 生成指纹ID，如 nmap-tcp-null-ssh-12
+func nmapFingerID(protocol, probe, service string, index int) string {
+	id := strings.ToLower(fmt.Sprintf("nmap-%s-%s-%s-%d", protocol, probe, service, index))
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, id)
+}
+
+// nmapYaml 返回转换后指纹的yaml结构，只包含设置的字段
+func (finger *Finger) nmapYaml() yaml.MapSlice {
+	rules := make(yaml.MapSlice, 0, len(finger.Rules))
+	for _, rule := range finger.Rules {
+		request := yaml.MapSlice{
+			{Key: "type", Value: rule.Value.Request.Type},
+			{Key: "data", Value: rule.Value.Request.Data},
+			{Key: "data-type", Value: rule.Value.Request.DataType},
+		}
+		if rule.Value.Request.ReadTimeout > 0 {
+			request = append(request, yaml.MapItem{Key: "read-timeout", Value: rule.Value.Request.ReadTimeout})
+		}
+		rules = append(rules, yaml.MapItem{Key: rule.Key, Value: yaml.MapSlice{
+			{Key: "request", Value: request},
+			{Key: "expression", Value: rule.Value.Expression},
+			{Key: "output", Value: rule.Value.Output},
+		}})
+	}
+	return yaml.MapSlice{
+		{Key: "id", Value: finger.Id},
+		{Key: "info", Value: yaml.MapSlice{
+			{Key: "name", Value: finger.Info.Name},
+			{Key: "author", Value: finger.Info.Author},
+			{Key: "description", Value: finger.Info.Description},
+			{Key: "tags", Value: finger.Info.Tags},
+		}},
+		{Key: "rules", Value: rules},
+		{Key: "expression", Value: finger.Expression},
+	}
+}
@@ -0,0 +1,93 @@
+/*
+  - Package finger
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: rawcache.go
+    @Date: 2025/6/22 下午4:05*
+*/
+package finger
+
+import (
+	"gxx/pkg/network"
+	"gxx/utils/proto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	rawCacheTTL      = time.Minute      // tcp/udp 响应的缓存时间
+	rawErrorCacheTTL = 30 * time.Second // 连接失败的缓存时间，避免对无法连接的目标重复尝试
+)
+
+var (
+	rawCache sync.Map // 缓存键 → *rawCacheEntry
+	rawGroup singleflight.Group
+)
+
+// rawCacheEntry tcp/udp 请求的缓存结果
+type rawCacheEntry struct {
+	request    *proto.Request
+	response   *proto.Response
+	fulltarget string
+	err        error
+}
+
+// apply 将缓存的请求与响应写入变量映射
+func (e *rawCacheEntry) apply(variableMap map[string]any) {
+	variableMap["request"] = e.request
+	variableMap["response"] = e.response
+	variableMap["fulltarget"] = e.fulltarget
+}
+
+// rawCacheKey 生成 tcp/udp 请求的缓存键，连接地址、发送数据与连接配置均相同的请求共享响应
+func rawCacheKey(reqType, address, data string, conf network.TcpOrUdpConfig) string {
+	return strings.Join([]string{
+		reqType,
+		address,
+		data,
+		strconv.Itoa(conf.ReadSize),
+		conf.ReadTimeout.String(),
+		conf.ProxyURL,
+		strconv.FormatBool(conf.IsLts),
+		conf.ServerName,
+		conf.TLSFingerprint,
+	}, "|")
+}
+
+// cachedRawRequest 相同的 tcp/udp 请求在缓存时间内只发送一次，并发请求合并为一次，
+// 用于大量指纹（如导入的 nmap 服务探针）发送相同探测数据的场景；send 将请求与响应写入传入的变量映射
+func cachedRawRequest(key string, variableMap map[string]any, send func(result map[string]any) error) error {
+	if cached, ok := rawCache.Load(key); ok {
+		entry := cached.(*rawCacheEntry)
+		if entry.err != nil {
+			return entry.err
+		}
+		entry.apply(variableMap)
+		return nil
+	}
+
+	result, _, _ := rawGroup.Do(key, func() (any, error) {
+		values := make(map[string]any, 3)
+		entry := &rawCacheEntry{err: send(values)}
+		ttl := rawErrorCacheTTL
+		if entry.err == nil {
+			entry.request, _ = values["request"].(*proto.Request)
+			entry.response, _ = values["response"].(*proto.Response)
+			entry.fulltarget, _ = values["fulltarget"].(string)
+			ttl = rawCacheTTL
+		}
+		rawCache.Store(key, entry)
+		time.AfterFunc(ttl, func() { rawCache.Delete(key) })
+		return entry, nil
+	})
+	entry := result.(*rawCacheEntry)
+	if entry.err != nil {
+		return entry.err
+	}
+	entry.apply(variableMap)
+	return nil
+}
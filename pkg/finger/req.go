@@ -14,6 +14,7 @@ import (
 	"gxx/utils/logger"
 	"gxx/utils/proto"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -70,20 +71,27 @@ func buildProtoRequest(resp *http.Response, req RuleRequest) *proto.Request {
 	return protoReq
 }
 
-// buildProtoResponse 构造proto.Response结构体
-func buildProtoResponse(resp *http.Response, utf8RespBody string, latency int64, proxy string) *proto.Response {
-	headers := make(map[string]string)
+// buildProtoResponse 构造proto.Response结构体，timing 与 connInfo 为请求耗时与连接记录，可以为空，
+// vhost 为目标的虚拟主机，抓取同一目标地址上的icon时使用；body 原样保存，按字符集解码后的文本保存在 Text 中
+func buildProtoResponse(resp *http.Response, body []byte, timing *network.Timing, connInfo *network.ConnInfo, proxy, vhost string) *proto.Response {
+	headers, headerValues := network.Header2Proto(resp.Header)
+	cookies, cookieAttributes := network.Cookies2Proto(resp.Header)
+	text, charset := network.DecodeText(body, resp.Header.Get("Content-Type"))
+	if charset != "" && charset != network.CharsetUTF8 {
+		logger.Debug(fmt.Sprintf("响应体已按 %s 解码为UTF-8文本", charset))
+	}
 	rawHeaderBuilder := strings.Builder{}
 	rawHeaderBuilder.WriteString(resp.Proto)
 	rawHeaderBuilder.WriteString(" ")
 	rawHeaderBuilder.WriteString(resp.Status)
 	rawHeaderBuilder.WriteString("\n")
-	for k := range resp.Header {
-		headers[strings.ToLower(k)] = resp.Header.Get(k)
-		rawHeaderBuilder.WriteString(k)
-		rawHeaderBuilder.WriteString(": ")
-		rawHeaderBuilder.WriteString(resp.Header.Get(k))
-		rawHeaderBuilder.WriteString("\n")
+	for k, values := range resp.Header {
+		for _, v := range values {
+			rawHeaderBuilder.WriteString(k)
+			rawHeaderBuilder.WriteString(": ")
+			rawHeaderBuilder.WriteString(v)
+			rawHeaderBuilder.WriteString("\n")
+		}
 	}
 	// 仅在首页HTML且为GET请求时尝试解析/抓取favicon，避免在高并发下重复抓取导致内存与网络开销暴涨
 	var iconHashStr = ""
@@ -91,22 +99,32 @@ func buildProtoResponse(resp *http.Response, utf8RespBody string, latency int64,
 		path := resp.Request.URL.Path
 		ct := resp.Header.Get("Content-Type")
 		if (path == "" || path == "/") && strings.Contains(strings.ToLower(ct), "text/html") {
-			iconUrl := GetIconURL(resp.Request.URL.String(), utf8RespBody)
+			iconUrl := GetIconURL(resp.Request.URL.String(), text)
 			logger.Debug(fmt.Sprintf("提取到iconUrl为: %s", iconUrl))
-			iconHashStr = NewGetIconHash(iconUrl, proxy).Run()
+			iconHash := NewGetIconHash(iconUrl, proxy)
+			if u, err := url.Parse(iconUrl); err == nil && strings.EqualFold(u.Hostname(), resp.Request.URL.Hostname()) {
+				iconHash.vhost = vhost
+			}
+			iconHashStr = iconHash.Run()
 			logger.Debug(fmt.Sprintf("icon hash：%s", iconHashStr))
 		}
 	}
 	return &proto.Response{
-		Status:      int32(resp.StatusCode),
-		Url:         network.Url2ProtoUrl(resp.Request.URL),
-		Headers:     headers,
-		ContentType: resp.Header.Get("Content-Type"),
-		Body:        []byte(utf8RespBody),
-		Raw:         []byte(fmt.Sprintf("%s\n\n%s", strings.Trim(rawHeaderBuilder.String(), "\n"), utf8RespBody)),
-		RawHeader:   []byte(strings.Trim(rawHeaderBuilder.String(), "\n")),
-		Latency:     latency,
-		IconHash:    iconHashStr,
+		Status:           int32(resp.StatusCode),
+		Url:              network.Url2ProtoUrl(resp.Request.URL),
+		Headers:          headers,
+		ContentType:      resp.Header.Get("Content-Type"),
+		Body:             body,
+		Text:             text,
+		Raw:              []byte(fmt.Sprintf("%s\n\n%s", strings.Trim(rawHeaderBuilder.String(), "\n"), body)),
+		RawHeader:        []byte(strings.Trim(rawHeaderBuilder.String(), "\n")),
+		Latency:          timing.Latency(),
+		IconHash:         iconHashStr,
+		Cookies:          cookies,
+		CookieAttributes: cookieAttributes,
+		HeaderValues:     headerValues,
+		Timing:           timing.ToProto(),
+		Conn:             connInfo.ToProto(resp.TLS),
 	}
 }
 
@@ -120,6 +138,6 @@ func BuildProtoRequest(resp *http.Response, method, body, path string) *proto.Re
 }
 
 // BuildProtoResponse 构造proto.Response结构体 (公开版本)
-func BuildProtoResponse(resp *http.Response, utf8RespBody string, latency int64, proxy string) *proto.Response {
-	return buildProtoResponse(resp, utf8RespBody, latency, proxy)
+func BuildProtoResponse(resp *http.Response, body []byte, timing *network.Timing, connInfo *network.ConnInfo, proxy, vhost string) *proto.Response {
+	return buildProtoResponse(resp, body, timing, connInfo, proxy, vhost)
 }
@@ -12,8 +12,9 @@ import (
 	"gxx/pkg/network"
 	"gxx/utils/common"
 	"gxx/utils/logger"
+	"gxx/utils/proto"
 	"io"
-	"net/http/httptrace"
+	"net"
 	"net/url"
 	"strings"
 	"time"
@@ -22,13 +23,12 @@ import (
 )
 
 var (
-	// 本地上限与 network.MaxDefaultBody 对齐，由调用方统一限制
-	maxDefaultBody int64 = 512 * 1024 // 512KB
-	defaultTimeout       = 5 * time.Second
+	defaultTimeout = 5 * time.Second
 )
 
-// SendRequest yaml poc发送http请求
-func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[string]any, proxy string, timeout int) (map[string]any, error) {
+// SendRequest yaml poc发送http请求，session不为空时在规则之间共享cookie，tlsFingerprint 为指纹配置的TLS客户端指纹，为空时使用全局配置，
+// vhost 为目标的虚拟主机，不为空时作为请求的 Host 与 SNI；errs 不为空时记录请求失败的次数与错误分类
+func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[string]any, proxy string, timeout int, session *Session, tlsFingerprint, vhost string, errs *network.ErrorStats) (map[string]any, error) {
 
 	// 设置超时时间，如果传入的超时时间为0，则使用默认超时时间
 	timeoutDuration := time.Duration(timeout) * time.Second
@@ -37,26 +37,18 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 	}
 
 	options := network.OptionsRequest{
-		Proxy:              "",              // 初始化为空，后面设置
+		Proxy:              proxy,           // 代理配置，代理列表由代理池按策略选择
 		Timeout:            timeoutDuration, // 使用确定的超时参数
-		Retries:            2,               // 增加重试次数
 		FollowRedirects:    !rule.Request.FollowRedirects,
 		InsecureSkipVerify: true, // 忽略SSL证书错误
 		CustomHeaders:      map[string]string{},
+		CookieJar:          session.cookieJar(),
+		Timing:             network.NewTiming(),
+		ConnInfo:           network.NewConnInfo(),
+		TLSFingerprint:     tlsFingerprint,
+		VHost:              vhost,
+		Errors:             errs,
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
-	defer cancel() // 在读取完响应后取消
-
-	// 设置代理地址
-	if proxy != "" {
-		proxyURL, err := url.Parse(proxy)
-		if err != nil {
-			fmt.Println("代理地址解析失败:", err)
-		} else {
-			options.Proxy = proxyURL.String()
-		}
-	}
-
 	// 处理path
 	newPath := formatPath(rule.Request.Path)
 
@@ -75,24 +67,38 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 	reqType := strings.ToLower(rule.Request.Type)
 	if len(reqType) > 0 && reqType != common.HttpType {
 		switch reqType {
-		case common.TcpType:
-			rule.Request.Host = SetVariableMap(rule.Request.Host, variableMap)
-			info, err := common.ParseAddress(rule.Request.Host)
+		case common.TcpType, common.SslType:
+			// ssl 为基于TLS的TCP请求
+			address, info, err := ruleAddress(rule.Request.Host, target, variableMap)
 			if err != nil {
 				return nil, fmt.Errorf("Error parsing address: %v\n", err)
 			}
-			nc, err := network.NewTcpClient(rule.Request.Host, network.TcpOrUdpConfig{
-				Network:     rule.Request.Type,
-				ReadTimeout: time.Duration(rule.Request.ReadTimeout),
-				ReadSize:    rule.Request.ReadSize,
-				MaxRetries:  1,
-				ProxyURL:    options.Proxy,
-				IsLts:       info.IsLts,
-				ServerName:  info.Host,
-			})
-			if err != nil {
-				logger.Debug(fmt.Sprintf("tcp error：%s", err.Error()))
-				return nil, err
+			conf := network.TcpOrUdpConfig{
+				Network:        rule.Request.Type,
+				ReadTimeout:    time.Duration(rule.Request.ReadTimeout) * time.Second,
+				ReadSize:       rule.Request.ReadSize,
+				ProxyURL:       options.Proxy,
+				IsLts:          info.IsLts || reqType == common.SslType,
+				ServerName:     serverName(info.Host, target, options.VHost),
+				TLSFingerprint: options.TLSFingerprint,
+				Errors:         options.Errors,
+			}
+			if len(rule.Request.Steps) > 0 {
+				nc, err := network.NewTcpClient(address, conf)
+				if err != nil {
+					logger.Debug(fmt.Sprintf("tcp error：%s", err.Error()))
+					return nil, err
+				}
+				sent, res, steps := runSteps(nc, rule.Request.Steps, variableMap)
+				_ = nc.Close()
+				err = network.RawParse(nc, sent, res, variableMap)
+				if err != nil {
+					logger.Debug(fmt.Sprintf("tcp parse error：%s", err.Error()))
+				}
+				if response, ok := variableMap["response"].(*proto.Response); ok {
+					response.Steps = steps
+				}
+				return variableMap, nil
 			}
 			data := rule.Request.Data
 
@@ -102,39 +108,46 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 					data = common.FromHex(data)
 				}
 			}
-			logger.Debug(fmt.Sprintf("TCP发送数据：%s", data))
-			errs := nc.Send([]byte(data))
-			if errs != nil {
-				logger.Debug(fmt.Sprintf("tcp send error：%s", errs.Error()))
-			}
-			res, err := nc.RecvTcp()
-			if err != nil {
-				logger.Debug(fmt.Sprintf("tcp receive error：%s", err.Error()))
-			}
-			_ = nc.Close()
-			err = network.RawParse(nc, []byte(data), res, variableMap)
+			err = cachedRawRequest(rawCacheKey(reqType, address, data, conf), variableMap, func(result map[string]any) error {
+				nc, err := network.NewTcpClient(address, conf)
+				if err != nil {
+					logger.Debug(fmt.Sprintf("tcp error：%s", err.Error()))
+					return err
+				}
+				logger.Debug(fmt.Sprintf("TCP发送数据：%s", data))
+				errs := nc.Send([]byte(data))
+				if errs != nil {
+					logger.Debug(fmt.Sprintf("tcp send error：%s", errs.Error()))
+				}
+				res, err := nc.RecvTcp()
+				if err != nil {
+					logger.Debug(fmt.Sprintf("tcp receive error：%s", err.Error()))
+				}
+				_ = nc.Close()
+				err = network.RawParse(nc, []byte(data), res, result)
+				if err != nil {
+					logger.Debug(fmt.Sprintf("tcp or udp parse error：%s", err.Error()))
+				}
+				return nil
+			})
 			if err != nil {
-				logger.Debug(fmt.Sprintf("tcp or udp parse error：%s", err.Error()))
+				return nil, err
 			}
 			return variableMap, nil
 		case common.UdpType:
-			rule.Request.Host = SetVariableMap(rule.Request.Host, variableMap)
-			info, err := common.ParseAddress(rule.Request.Host)
+			address, info, err := ruleAddress(rule.Request.Host, target, variableMap)
 			if err != nil {
 				return nil, fmt.Errorf("Error parsing address: %v\n", err)
 			}
-			nc, err := network.NewUdpClient(rule.Request.Host, network.TcpOrUdpConfig{
-				Network:     rule.Request.Type,
-				ReadTimeout: time.Duration(rule.Request.ReadTimeout),
-				ReadSize:    rule.Request.ReadSize,
-				MaxRetries:  1,
-				ProxyURL:    options.Proxy,
-				IsLts:       info.IsLts,
-				ServerName:  info.Host,
-			})
-			if err != nil {
-				logger.Debug(fmt.Sprintf("udp error：%s", err.Error()))
-				return nil, err
+			conf := network.TcpOrUdpConfig{
+				Network:        rule.Request.Type,
+				ReadTimeout:    time.Duration(rule.Request.ReadTimeout) * time.Second,
+				ReadSize:       rule.Request.ReadSize,
+				ProxyURL:       options.Proxy,
+				IsLts:          info.IsLts,
+				ServerName:     serverName(info.Host, target, options.VHost),
+				TLSFingerprint: options.TLSFingerprint,
+				Errors:         options.Errors,
 			}
 			data := rule.Request.Data
 
@@ -144,18 +157,29 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 					data = common.FromHex(data)
 				}
 			}
-			errs := nc.Send([]byte(data))
-			if errs != nil {
-				fmt.Println("udp send error:", errs.Error())
-			}
-			res, err := nc.RecvTcp()
-			if err != nil {
-				fmt.Println("udp receive error:", err.Error())
-			}
-			_ = nc.Close()
-			err = network.RawParse(nc, []byte(data), res, variableMap)
+			err = cachedRawRequest(rawCacheKey(reqType, address, data, conf), variableMap, func(result map[string]any) error {
+				nc, err := network.NewUdpClient(address, conf)
+				if err != nil {
+					logger.Debug(fmt.Sprintf("udp error：%s", err.Error()))
+					return err
+				}
+				errs := nc.Send([]byte(data))
+				if errs != nil {
+					logger.Debug(fmt.Sprintf("udp send error：%s", errs.Error()))
+				}
+				res, err := nc.RecvTcp()
+				if err != nil {
+					logger.Debug(fmt.Sprintf("udp receive error：%s", err.Error()))
+				}
+				_ = nc.Close()
+				err = network.RawParse(nc, []byte(data), res, result)
+				if err != nil {
+					logger.Debug(fmt.Sprintf("udp parse error：%s", err.Error()))
+				}
+				return nil
+			})
 			if err != nil {
-				fmt.Println("udp or udp parse error:", err.Error())
+				return nil, err
 			}
 			return variableMap, nil
 		case common.GoType:
@@ -166,11 +190,18 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 		if len(rule.Request.Raw) > 0 {
 			// 执行raw格式请求
 			fmt.Println("执行raw格式请求")
-			rt := network.RawHttp{RawhttpClient: network.GetRawHTTP(int(options.Timeout))}
-			err := rt.RawHttpRequest(rule.Request.Raw, target, variableMap)
+			rawClient, err := network.GetRawHTTPProxy(int(timeoutDuration/time.Second), options.Proxy, target)
 			if err != nil {
 				return variableMap, err
 			}
+			rt := network.RawHttp{RawhttpClient: rawClient, CookieJar: options.CookieJar, Timing: options.Timing, VHost: options.VHost, MaxBody: int64(rule.Request.MaxBody)}
+			err = rt.RawHttpRequest(rule.Request.Raw, target, variableMap)
+			if err != nil {
+				return variableMap, err
+			}
+			if session != nil {
+				variableMap["session"] = session.ToProto(target)
+			}
 			return variableMap, nil
 		}
 	}
@@ -186,6 +217,14 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 
 	logger.Debug(fmt.Sprintf("请求URL：%s", NewUrlStr))
 
+	// 等待主机并发槽位后再开始计时，排队时间不计入请求超时，超时时间包含全部重试
+	// 槽位在读取完响应体后释放，构造响应时抓取icon会再次获取同一主机的槽位
+	release := network.AcquireHostSlot(NewUrlStr)
+	defer release()
+	options.Timing.Restart()
+	ctx, cancel := context.WithTimeout(context.Background(), network.Retry.Budget(options.Timeout))
+	defer cancel() // 在读取完响应后取消
+
 	// 发送请求
 	resp, err := network.SendRequestHttp(ctx, req.Method, NewUrlStr, rule.Request.Body, options)
 	if err != nil {
@@ -201,25 +240,51 @@ func SendRequest(target string, req RuleRequest, rule Rule, variableMap map[stri
 	protoReq := buildProtoRequest(resp, rule.Request)
 	variableMap["request"] = protoReq
 
-	// 读取响应体
-	reader := io.LimitReader(resp.Body, maxDefaultBody)
-	body, err := io.ReadAll(reader)
+	// 读取并解压响应体，规则设置了 max-body 时使用规则的读取上限
+	body, truncated, err := network.ReadBody(resp, int64(rule.Request.MaxBody))
 	if err != nil {
 		logger.Debug(fmt.Sprintf("读取响应体出错：%s", err))
 		// 即使读取响应体出错，也继续处理，使用空响应体
 		body = []byte{}
 	}
-	utf8RespBody := common.Str2UTF8(string(body))
-
-	// 计算响应时间
-	var milliseconds int64
-	start := time.Now()
-	trace := httptrace.ClientTrace{}
-	trace.GotFirstResponseByte = func() {
-		milliseconds = time.Since(start).Nanoseconds() / 1e6
-	}
+	options.Timing.Done()
+	release()
+
 	// 处理响应的raw，传入代理参数
-	protoResp := buildProtoResponse(resp, utf8RespBody, milliseconds, proxy)
+	protoResp := buildProtoResponse(resp, body, options.Timing, options.ConnInfo, proxy, options.VHost)
+	protoResp.Truncated = truncated
 	variableMap["response"] = protoResp
+	if session != nil {
+		variableMap["session"] = session.ToProto(NewUrlStr)
+	}
 	return variableMap, nil
 }
+
+// ruleAddress 返回 tcp/udp 请求的连接地址与解析结果，host 为空时使用目标地址（目标为 https 时使用TLS）
+func ruleAddress(host, target string, variableMap map[string]any) (string, common.AddressInfo, error) {
+	if host == "" {
+		info, err := common.ParseAddress(target)
+		if err != nil {
+			return "", info, err
+		}
+		return net.JoinHostPort(info.Host, info.Port), info, nil
+	}
+	host = SetVariableMap(host, variableMap)
+	info, err := common.ParseAddress(host)
+	return host, info, err
+}
+
+// serverName 返回TCP/UDP请求的 SNI，连接目标地址且配置了虚拟主机时使用虚拟主机名
+func serverName(host, target, vhost string) string {
+	if vhost == "" {
+		return host
+	}
+	u, err := url.Parse(target)
+	if err != nil || !strings.EqualFold(u.Hostname(), host) {
+		return host
+	}
+	if name, _, err := net.SplitHostPort(vhost); err == nil {
+		return name
+	}
+	return vhost
+}
@@ -0,0 +1,50 @@
+/*
+  - Package finger
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: runner_test.go
+    @Date: 2025/6/26 上午10:10*
+*/
+package finger
+
+import (
+	"gxx/pkg/network"
+	"gxx/utils/proto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendRequestLatencyExcludesRateLimitWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	// 每秒1个请求，第二个请求需要在限速器中排队约1秒
+	network.SetRateLimit(network.RateLimitOptions{HostRate: 1})
+	defer network.SetRateLimit(network.RateLimitOptions{})
+
+	rule := Rule{Request: RuleRequest{Method: http.MethodGet, Path: "/latency"}}
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		variableMap, err := SendRequest(srv.URL, rule.Request, rule, map[string]any{}, "", 5, nil, "", "", nil)
+		if err != nil {
+			t.Fatalf("第%d次请求失败: %v", i+1, err)
+		}
+		elapsed := time.Since(start)
+		resp, ok := variableMap["response"].(*proto.Response)
+		if !ok {
+			t.Fatalf("第%d次请求没有响应", i+1)
+		}
+		if i == 1 && elapsed < 500*time.Millisecond {
+			t.Fatalf("第二次请求应在限速器中排队，实际耗时 %v", elapsed)
+		}
+		if resp.Latency >= 500 || resp.Timing.GetTotal() >= 500 {
+			t.Errorf("第%d次请求的耗时包含了排队时间：latency=%dms，total=%dms，实际耗时 %v",
+				i+1, resp.Latency, resp.Timing.GetTotal(), elapsed)
+		}
+	}
+}
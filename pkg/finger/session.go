@@ -0,0 +1,111 @@
+/*
+  - Package finger
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: session.go
+    @Date: 2025/6/12 上午10:21*
+*/
+package finger
+
+import (
+	"gxx/utils/proto"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+)
+
+// Session 单个指纹评估期间共享的会话状态，规则之间通过cookie jar保持Set-Cookie
+type Session struct {
+	Jar http.CookieJar
+	// requested 会话中是否存在规则实际发送请求获得的cookie，缓存响应写入的cookie不计入
+	requested bool
+}
+
+// requestJar 规则实际发送请求时使用的cookie jar，写入cookie时标记会话依赖实际请求
+type requestJar struct {
+	session *Session
+}
+
+// Cookies 返回会话中目标地址可用的cookie
+func (j requestJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.session.Jar.Cookies(u)
+}
+
+// SetCookies 写入实际请求响应中的cookie
+func (j requestJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if len(cookies) > 0 {
+		j.session.requested = true
+	}
+	j.session.Jar.SetCookies(u, cookies)
+}
+
+// NewSession 创建新的会话，每个指纹评估使用独立的cookie jar
+func NewSession() *Session {
+	jar, _ := cookiejar.New(nil)
+	return &Session{Jar: jar}
+}
+
+// UseSession 判断指纹是否启用会话，未配置时默认启用，仅 session: false 时关闭
+func (finger *Finger) UseSession() bool {
+	return finger.Session == nil || *finger.Session
+}
+
+// cookieJar 返回规则发送请求使用的cookie jar，会话为空时返回nil
+func (s *Session) cookieJar() http.CookieJar {
+	if s == nil || s.Jar == nil {
+		return nil
+	}
+	return requestJar{session: s}
+}
+
+// HasCookies 判断会话中是否已存在目标地址可用的cookie
+func (s *Session) HasCookies(target string) bool {
+	if s == nil || s.Jar == nil {
+		return false
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	return len(s.Jar.Cookies(u)) > 0
+}
+
+// HasRequestCookies 判断会话中是否存在规则实际请求获得的cookie且对目标地址可用，
+// 仅由缓存响应写入的cookie（如共享的首页响应）返回false
+func (s *Session) HasRequestCookies(target string) bool {
+	return s != nil && s.requested && s.HasCookies(target)
+}
+
+// ToProto 将会话中目标地址可用的cookie转换为CEL可访问的proto.Session
+func (s *Session) ToProto(target string) *proto.Session {
+	cookies := make(map[string]string)
+	if s != nil && s.Jar != nil {
+		if u, err := url.Parse(target); err == nil {
+			for _, c := range s.Jar.Cookies(u) {
+				cookies[c.Name] = c.Value
+			}
+		}
+	}
+	return &proto.Session{Cookies: cookies}
+}
+
+// AddResponseCookies 将响应（如缓存命中的响应）中的 Set-Cookie 写入会话，target 为响应对应的请求地址，
+// 使用缓存响应的规则与实际发送请求的规则一样更新会话状态，但不视为依赖实际请求的会话
+func (s *Session) AddResponseCookies(target string, resp *proto.Response) {
+	if s == nil || s.Jar == nil || resp == nil {
+		return
+	}
+	values, ok := resp.GetHeaderValues()["set-cookie"]
+	if !ok || len(values.GetValues()) == 0 {
+		return
+	}
+	if u := resp.GetUrl(); u.GetScheme() != "" && u.GetHost() != "" {
+		target = (&url.URL{Scheme: u.GetScheme(), Host: u.GetHost(), Path: u.GetPath()}).String()
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return
+	}
+	header := http.Header{"Set-Cookie": values.GetValues()}
+	s.Jar.SetCookies(u, (&http.Response{Header: header}).Cookies())
+}
@@ -0,0 +1,51 @@
+/*
+  - Package finger
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: session_test.go
+    @Date: 2025/6/26 下午2:30*
+*/
+package finger
+
+import (
+	"gxx/utils/proto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionRequestCookies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "token", Value: "login", Path: "/"})
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	// 缓存响应写入的cookie可用于后续请求，但不视为依赖实际请求的会话
+	session := NewSession()
+	cached := &proto.Response{HeaderValues: map[string]*proto.HeaderValues{
+		"set-cookie": {Values: []string{"JSESSIONID=cached; Path=/"}},
+	}}
+	session.AddResponseCookies(srv.URL+"/", cached)
+	if !session.HasCookies(srv.URL + "/api") {
+		t.Fatal("缓存响应的cookie应写入会话")
+	}
+	if session.HasRequestCookies(srv.URL + "/api") {
+		t.Error("仅由缓存响应写入的cookie不应视为实际请求获得的cookie")
+	}
+
+	// 规则实际请求获得的cookie标记会话依赖实际请求
+	rule := Rule{Request: RuleRequest{Method: http.MethodGet, Path: "/login"}}
+	if _, err := SendRequest(srv.URL, rule.Request, rule, map[string]any{}, "", 5, session, "", "", nil); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if !session.HasRequestCookies(srv.URL + "/api") {
+		t.Error("实际请求获得cookie后应视为依赖会话状态")
+	}
+	if got := session.ToProto(srv.URL + "/api").GetCookies(); got["token"] != "login" || got["JSESSIONID"] != "cached" {
+		t.Errorf("会话cookie不完整: %v", got)
+	}
+}
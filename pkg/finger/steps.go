@@ -0,0 +1,110 @@
+/*
+  - Package finger
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: steps.go
+    @Date: 2025/6/22 上午10:15*
+*/
+package finger
+
+import (
+	"bytes"
+	"fmt"
+	"gxx/pkg/network"
+	"gxx/utils/common"
+	"gxx/utils/logger"
+	"regexp"
+	"strings"
+)
+
+// maxStepReadSize read-until 单个步骤最多读取的字节数
+const maxStepReadSize = 64 * 1024
+
+// runSteps 在同一连接上按顺序执行多步会话，返回发送的全部数据、读取的全部数据以及每个步骤的数据；
+// 读取失败或 expect 不匹配时终止会话，已执行步骤的数据仍然返回
+func runSteps(nc *network.Client, steps []RuleStep, variableMap map[string]any) (sent []byte, received []byte, stepData [][]byte) {
+	var last []byte // 上一次读取的内容，供 expect 匹配
+	for i, step := range steps {
+		switch {
+		case step.Send != "":
+			data := []byte(decodeStepData(SetVariableMap(step.Send, variableMap), step.DataType))
+			logger.Debug(fmt.Sprintf("TCP步骤%d发送数据：%s", i, data))
+			stepData = append(stepData, data)
+			if err := nc.Send(data); err != nil {
+				logger.Debug(fmt.Sprintf("tcp step %d send error：%s", i, err.Error()))
+				return
+			}
+			sent = append(sent, data...)
+		case step.Read != nil:
+			data, err := nc.ReadN(*step.Read)
+			last = data
+			stepData = append(stepData, data)
+			received = append(received, data...)
+			if err != nil && len(data) == 0 {
+				logger.Debug(fmt.Sprintf("tcp step %d read error：%s", i, err.Error()))
+				return
+			}
+		case step.ReadUntil != "":
+			match, err := stepMatcher(step)
+			if err != nil {
+				logger.Debug(fmt.Sprintf("tcp step %d read-until error：%s", i, err.Error()))
+				return
+			}
+			data, err := nc.ReadUntil(match, maxStepReadSize)
+			last = data
+			stepData = append(stepData, data)
+			received = append(received, data...)
+			if err != nil {
+				logger.Debug(fmt.Sprintf("tcp step %d read-until error：%s", i, err.Error()))
+				return
+			}
+		case step.Expect != "":
+			re, err := regexp.Compile(step.Expect)
+			if err != nil {
+				logger.Debug(fmt.Sprintf("tcp step %d expect error：%s", i, err.Error()))
+				return
+			}
+			matched := re.Find(last)
+			if matched == nil {
+				logger.Debug(fmt.Sprintf("TCP步骤%d expect 不匹配，终止会话：%s", i, step.Expect))
+				return
+			}
+			stepData = append(stepData, matched)
+		default:
+			logger.Debug(fmt.Sprintf("TCP步骤%d未设置 send、read、read-until 或 expect，已跳过", i))
+			stepData = append(stepData, []byte{})
+		}
+	}
+	return
+}
+
+// stepMatcher 返回 read-until 的匹配函数，匹配成功时返回匹配结束的位置，否则返回-1
+func stepMatcher(step RuleStep) (func([]byte) int, error) {
+	if step.Regex {
+		re, err := regexp.Compile(step.ReadUntil)
+		if err != nil {
+			return nil, err
+		}
+		return func(data []byte) int {
+			if loc := re.FindIndex(data); loc != nil {
+				return loc[1]
+			}
+			return -1
+		}, nil
+	}
+	delim := []byte(decodeStepData(step.ReadUntil, step.DataType))
+	return func(data []byte) int {
+		if idx := bytes.Index(data, delim); idx >= 0 {
+			return idx + len(delim)
+		}
+		return -1
+	}, nil
+}
+
+// decodeStepData 按数据类型转换步骤中的数据，hex 时解码为原始字节
+func decodeStepData(data, dataType string) string {
+	if strings.ToLower(dataType) == "hex" {
+		return common.FromHex(data)
+	}
+	return data
+}
@@ -9,7 +9,7 @@ package finger
 
 import (
 	"fmt"
-	"gxx/utils/common"
+	"gxx/pkg/network"
 	"gxx/utils/logger"
 	"io"
 	"net/http"
@@ -19,8 +19,8 @@ import (
 	"unicode"
 )
 
-// GetTitle 从网页中提取标题
-func GetTitle(urlStr string, resp *http.Response) string {
+// GetTitle 从网页中提取标题，proxy 为获取i18n文件时使用的代理配置
+func GetTitle(urlStr string, resp *http.Response, proxy string) string {
 	// 读取响应体
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -30,36 +30,12 @@ func GetTitle(urlStr string, resp *http.Response) string {
 	// 不要忘记恢复响应体以便后续使用
 	resp.Body = io.NopCloser(strings.NewReader(string(bodyBytes)))
 
-	// 解析字符集并转换编码
-	bodyText := string(bodyBytes)
-	contentType := resp.Header.Get("Content-Type")
-
-	// 检查和处理编码
-	charsetRegex := regexp.MustCompile(`(?i)charset=["']?([\w-]+)["']?`)
-	charsetMatch := charsetRegex.FindStringSubmatch(contentType)
-	if len(charsetMatch) < 2 {
-		// 如果 HTTP 头中没有指定字符集，尝试从 HTML 内容中查找
-		metaCharsetRegex := regexp.MustCompile(`(?i)<meta\s+.*?charset=["']?([\w-]+)["']?.*?>`)
-		metaMatch := metaCharsetRegex.FindStringSubmatch(bodyText)
-		if len(metaMatch) >= 2 {
-			charsetMatch = metaMatch
-		}
-	}
-
-	// 根据检测到的字符集进行转换
-	if len(charsetMatch) >= 2 {
-		charset := strings.ToLower(charsetMatch[1])
-		logger.Debug("检测到字符集: %s", charset)
-
-		if charset != "utf-8" && charset != "utf8" {
-			// 使用 common.Str2UTF8 函数转换为 UTF-8
-			bodyText = common.Str2UTF8(bodyText)
-			logger.Debug("已将内容从 %s 转换为 UTF-8", charset)
-		}
-	} else {
-		// 如果无法检测到字符集，尝试转换为 UTF-8
-		bodyText = common.Str2UTF8(bodyText)
+	// 按字符集（BOM、Content-Type、<meta> 声明或统计识别）解码为 UTF-8，二进制内容不提取标题
+	bodyText, charset := network.DecodeText(bodyBytes, resp.Header.Get("Content-Type"))
+	if bodyText == "" {
+		return ""
 	}
+	logger.Debug(fmt.Sprintf("检测到字符集: %s", charset))
 
 	// 解析URL
 	parsedURL, err := url.Parse(urlStr)
@@ -135,6 +111,8 @@ func GetTitle(urlStr string, resp *http.Response) string {
 		retries := 3
 		for i := 0; i < retries; i++ {
 			client := &http.Client{
+				Transport: network.RateLimited(network.ProxyTransport(proxy, titleURL)),
+				Timeout:   network.DefaultTimeout,
 				CheckRedirect: func(req *http.Request, via []*http.Request) error {
 					return nil // 允许重定向
 				},
@@ -158,15 +136,15 @@ func GetTitle(urlStr string, resp *http.Response) string {
 			}
 
 			if respTitle.StatusCode == 200 {
-				bodyBytes, err := io.ReadAll(respTitle.Body)
+				bodyBytes, _, err := network.ReadBody(respTitle, network.MaxDefaultBody)
 				_ = respTitle.Body.Close()
 				if err != nil {
 					logger.Debug("读取i18n JS响应出错: %v", err)
 					continue
 				}
 
-				// 将 JS 文件内容转换为 UTF-8
-				jsContent := common.Str2UTF8(string(bodyBytes))
+				// 将 JS 文件内容按字符集解码为 UTF-8
+				jsContent, _ := network.DecodeText(bodyBytes, respTitle.Header.Get("Content-Type"))
 
 				titleRegex := regexp.MustCompile(`"top\.login\.title": "(.*?)",`)
 				titleMatches := titleRegex.FindStringSubmatch(jsContent)
@@ -185,9 +163,6 @@ func GetTitle(urlStr string, resp *http.Response) string {
 
 // cleanTitle 移除空白字符并清理标题字符串
 func cleanTitle(title string) string {
-	// 先确保标题是UTF-8编码
-	title = common.Str2UTF8(title)
-
 	// 移除制表符、换行符和回车符
 	title = strings.Map(func(r rune) rune {
 		if r == '\r' || r == '\n' || r == '\t' {
@@ -32,14 +32,16 @@ const (
 var order = 0
 
 type Finger struct {
-	Id         string        `yaml:"id"`        //  脚本名称
-	Transport  string        `yaml:"transport"` // 传输方式，该字段用于指定发送数据包的协议，该字段用于指定发送数据包的协议:①tcp ②udp ③http
-	Set        yaml.MapSlice `yaml:"set"`       // 全局变量定义，该字段用于定义全局变量。比如随机数，反连平台等
-	Payloads   Payloads      `yaml:"payloads"`
-	Rules      RuleMapSlice  `yaml:"rules"`
-	Expression string        `yaml:"expression"`
-	Info       Info          `yaml:"info"`
-	Gopoc      string        `yaml:"gopoc"` // Gopoc 脚本名称
+	Id             string        `yaml:"id"`        //  脚本名称
+	Transport      string        `yaml:"transport"` // 传输方式，该字段用于指定发送数据包的协议，该字段用于指定发送数据包的协议:①tcp ②udp ③http
+	Set            yaml.MapSlice `yaml:"set"`       // 全局变量定义，该字段用于定义全局变量。比如随机数，反连平台等
+	Payloads       Payloads      `yaml:"payloads"`
+	Rules          RuleMapSlice  `yaml:"rules"`
+	Expression     string        `yaml:"expression"`
+	Info           Info          `yaml:"info"`
+	Gopoc          string        `yaml:"gopoc"`           // Gopoc 脚本名称
+	Session        *bool         `yaml:"session"`         // 是否在规则之间保持会话cookie，默认开启，设置为false关闭
+	TLSFingerprint string        `yaml:"tls-fingerprint"` // TLS客户端指纹，可选：go、chrome、firefox、safari、randomized，为空时使用全局配置
 }
 type Payloads struct {
 	Continue bool          `yaml:"continue"`
@@ -71,13 +73,42 @@ type RuleRequest struct {
 	Data            string            `yaml:"data"`         // tcp/udp 发送的内容
 	DataType        string            `yaml:"data-type"`    // tcp/udp 发送的数据类型，默认字符串
 	ReadSize        int               `yaml:"read-size"`    // tcp/udp 读取内容的长度
-	ReadTimeout     int               `yaml:"read-timeout"` // tcp/udp专用
+	ReadTimeout     int               `yaml:"read-timeout"` // tcp/udp 读取超时时间，单位秒
+	Steps           []RuleStep        `yaml:"steps"`        // tcp/ssl 多步会话，设置后忽略 data，按顺序在同一连接上执行
 	Raw             string            `yaml:"raw"`          // raw 专用
 	Method          string            `yaml:"method"`
 	Path            string            `yaml:"path"`
 	Headers         map[string]string `yaml:"headers"`
 	Body            string            `yaml:"body"`
 	FollowRedirects bool              `yaml:"follow_redirects"` // 是否跟随重定向，默认跟随重定向
+	MaxBody         ByteSize          `yaml:"max-body"`         // http/raw 响应体读取上限，如 2MB，为0时使用全局配置 -max-body
+}
+
+// ByteSize 字节数，yaml 中可以写整数（字节）或带单位的字符串，如 512KB、2MB
+type ByteSize int64
+
+// UnmarshalYAML 解析字节数
+func (s *ByteSize) UnmarshalYAML(unmarshal func(any) error) error {
+	var raw string
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	size, err := common.ParseSize(raw)
+	if err != nil {
+		return err
+	}
+	*s = ByteSize(size)
+	return nil
+}
+
+// RuleStep tcp/ssl 多步会话中的一个步骤，每个步骤只设置 send、read、read-until、expect 其中之一
+type RuleStep struct {
+	Send      string `yaml:"send"`       // 发送的内容，支持 {{变量}}
+	Read      *int   `yaml:"read"`       // 读取一次数据的最大长度，为0时使用 read-size
+	ReadUntil string `yaml:"read-until"` // 持续读取直到出现该分隔符，regex 为 true 时为正则表达式
+	Regex     bool   `yaml:"regex"`      // read-until 是否为正则表达式
+	Expect    string `yaml:"expect"`     // 正则匹配上一次读取的内容，不匹配时终止会话
+	DataType  string `yaml:"data-type"`  // send 与 read-until 分隔符的数据类型，默认字符串，可选 hex
 }
 
 // Info 以下开始是 信息部分
@@ -0,0 +1,205 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: body.go
+    @Date: 2025/6/25 上午10:20*
+*/
+package network
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"gxx/utils/logger"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	DefaultMaxBody int64 = 512 * 1024 // 默认响应体读取上限，512KB
+	sniffSize            = 512        // 判断响应体是否为文本时检查的字节数
+)
+
+// 响应体压缩方式
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingBrotli  = "br"
+)
+
+// AcceptEncoding 请求头预设声明支持的压缩方式，响应体由 ReadBody 解压
+const AcceptEncoding = "gzip, deflate, br"
+
+// ReadBody 读取并解压响应体，limit 为解压后的读取上限（字节），不大于0时使用 MaxDefaultBody；
+// truncated 为true表示响应体超过上限被截断。解压依据 Content-Encoding 响应头，
+// 响应头缺失时根据响应体特征识别 gzip、deflate 与 brotli 压缩，无法解压时返回原始响应体
+func ReadBody(resp *http.Response, limit int64) (body []byte, truncated bool, err error) {
+	if limit <= 0 {
+		limit = MaxDefaultBody
+	}
+	// 多读取1字节用于判断是否超过上限
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	truncated = int64(len(raw)) > limit
+	if truncated {
+		raw = raw[:limit]
+	}
+
+	body, encoding := DecodeBody(raw, resp.Header.Get("Content-Encoding"), resp.Header.Get("Content-Type"), limit+1, truncated)
+	if encoding != "" {
+		logger.Debug(fmt.Sprintf("响应体已按 %s 解压，压缩前 %d 字节，解压后 %d 字节", encoding, len(raw), len(body)))
+	}
+	if int64(len(body)) > limit {
+		body, truncated = body[:limit], true
+	}
+	if truncated {
+		logger.Debug(fmt.Sprintf("响应体超过读取上限 %d 字节，已截断", limit))
+	}
+	return body, truncated, nil
+}
+
+// DecodeBody 按 Content-Encoding 解压响应体，最多返回 limit 字节（不大于0时不限制），返回解压后的内容与实际使用的压缩方式；
+// Content-Encoding 为空或为 identity 时根据响应体特征识别压缩方式（仅限 contentType 为空或为文本类型）；
+// partial 为true表示 data 已被截断，此时接受不完整压缩流解出的内容。解压失败时返回原始内容，压缩方式为空
+func DecodeBody(data []byte, contentEncoding, contentType string, limit int64, partial bool) ([]byte, string) {
+	if len(data) == 0 {
+		return data, ""
+	}
+	encodings := parseContentEncoding(contentEncoding)
+	if len(encodings) == 0 {
+		for _, encoding := range sniffEncoding(data, contentType) {
+			decoded, err := decodeWith(data, encoding, limit)
+			if decodeAccepted(decoded, err, limit, partial) && looksLikeText(decoded) {
+				return decoded, encoding
+			}
+		}
+		return data, ""
+	}
+
+	// 多重压缩按声明的相反顺序解压
+	decoded := data
+	for i := len(encodings) - 1; i >= 0; i-- {
+		out, err := decodeWith(decoded, encodings[i], limit)
+		// 部分服务端声明了压缩但实际返回未压缩内容，此时保留原始内容
+		if !decodeAccepted(out, err, limit, true) {
+			logger.Debug(fmt.Sprintf("响应体按 %s 解压失败，使用原始响应体: %v", encodings[i], err))
+			return data, ""
+		}
+		decoded = out
+	}
+	return decoded, strings.Join(encodings, ", ")
+}
+
+// parseContentEncoding 解析 Content-Encoding 响应头，忽略 identity
+func parseContentEncoding(value string) []string {
+	var encodings []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" || part == "identity" {
+			continue
+		}
+		encodings = append(encodings, part)
+	}
+	return encodings
+}
+
+// sniffEncoding 根据响应体特征返回未声明的压缩方式的候选列表，仅在响应类型为文本（或未声明）而内容不像文本时识别，
+// 避免解压 .gz 等压缩文件下载：gzip 与 zlib 有固定的文件头，brotli 与原始 deflate 没有文件头时依次尝试，解压结果须像文本
+func sniffEncoding(data []byte, contentType string) []string {
+	if !isTextType(contentType) || looksLikeText(data) {
+		return nil
+	}
+	if len(data) >= 2 {
+		if data[0] == 0x1f && data[1] == 0x8b {
+			return []string{EncodingGzip}
+		}
+		// zlib 头：压缩方法为8、窗口不大于32K，且前两个字节按大端序可被31整除
+		if data[0]&0x0f == 8 && data[0]>>4 <= 7 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0 {
+			return []string{EncodingDeflate}
+		}
+	}
+	return []string{EncodingBrotli, EncodingDeflate}
+}
+
+// decodeWith 使用指定压缩方式解压，最多读取 limit 字节
+func decodeWith(data []byte, encoding string, limit int64) ([]byte, error) {
+	var reader io.Reader
+	switch encoding {
+	case EncodingGzip, "x-gzip":
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gr.Close() }()
+		reader = gr
+	case EncodingDeflate:
+		// deflate 按规范应为 zlib 格式，但不少服务端直接返回原始 deflate 数据
+		if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+			defer func() { _ = zr.Close() }()
+			reader = zr
+		} else {
+			fr := flate.NewReader(bytes.NewReader(data))
+			defer func() { _ = fr.Close() }()
+			reader = fr
+		}
+	case EncodingBrotli:
+		reader = brotli.NewReader(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("不支持的压缩方式: %s", encoding)
+	}
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit)
+	}
+	return io.ReadAll(reader)
+}
+
+// decodeAccepted 判断解压结果是否可用：完整解压、达到读取上限，或 partial 为true时压缩流在截断处提前结束
+func decodeAccepted(out []byte, err error, limit int64, partial bool) bool {
+	switch {
+	case err == nil:
+		return true
+	case limit > 0 && int64(len(out)) >= limit:
+		return true
+	case partial && len(out) > 0 && errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+	return false
+}
+
+// isTextType 判断 Content-Type 是否为空或文本类型（text/*、json、javascript、xml）
+func isTextType(contentType string) bool {
+	if strings.TrimSpace(contentType) == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.Contains(mediaType, "json") ||
+		strings.Contains(mediaType, "javascript") ||
+		strings.Contains(mediaType, "xml")
+}
+
+// looksLikeText 判断内容开头是否像文本：不包含除制表、换行、回车、换页与 ESC 以外的控制字符，不要求为UTF-8
+func looksLikeText(data []byte) bool {
+	if len(data) > sniffSize {
+		data = data[:sniffSize]
+	}
+	for _, b := range data {
+		if (b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != 0x1b) || b == 0x7f {
+			return false
+		}
+	}
+	return true
+}
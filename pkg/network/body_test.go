@@ -0,0 +1,184 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: body_test.go
+    @Date: 2025/6/25 上午10:20*
+*/
+package network
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+// testPage 测试用的HTML页面，长度足够让压缩生效
+var testPage = []byte(strings.Repeat("<html><head><title>测试页面</title></head><body>BODY_SIGNATURE</body></html>\n", 64))
+
+// compress 按指定方式压缩数据，rawDeflate 为原始 deflate 数据（无 zlib 头）
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case EncodingGzip:
+		w = gzip.NewWriter(&buf)
+	case EncodingDeflate:
+		w = zlib.NewWriter(&buf)
+	case "rawDeflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = fw
+	case EncodingBrotli:
+		w = brotli.NewWriter(&buf)
+	default:
+		t.Fatalf("未知的压缩方式: %s", encoding)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newResponse 构造带响应头的测试响应
+func newResponse(body []byte, contentEncoding, contentType string) *http.Response {
+	header := http.Header{}
+	if contentEncoding != "" {
+		header.Set("Content-Encoding", contentEncoding)
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return &http.Response{Header: header, Body: io.NopCloser(bytes.NewReader(body))}
+}
+
+func TestReadBodyDecodes(t *testing.T) {
+	tests := []struct {
+		name            string
+		encoding        string // 压缩方式
+		contentEncoding string // 响应头声明的压缩方式
+		contentType     string
+	}{
+		{"gzip", EncodingGzip, "gzip", "text/html"},
+		{"gzip无响应头", EncodingGzip, "", "text/html"},
+		{"x-gzip", EncodingGzip, "x-gzip", "text/html"},
+		{"deflate", EncodingDeflate, "deflate", "text/html"},
+		{"deflate无响应头", EncodingDeflate, "", "text/html"},
+		{"原始deflate", "rawDeflate", "deflate", "text/html"},
+		{"原始deflate无响应头", "rawDeflate", "", "text/html"},
+		{"brotli", EncodingBrotli, "br", "text/html"},
+		{"brotli无响应头", EncodingBrotli, "", "text/html"},
+		{"brotli无响应头无类型", EncodingBrotli, "", ""},
+		{"声明大小写与空格", EncodingGzip, " GZIP ", "text/html; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newResponse(compress(t, tt.encoding, testPage), tt.contentEncoding, tt.contentType)
+			body, truncated, err := ReadBody(resp, DefaultMaxBody)
+			if err != nil {
+				t.Fatalf("ReadBody 返回错误: %v", err)
+			}
+			if truncated {
+				t.Error("响应体未超过上限，不应标记截断")
+			}
+			if !bytes.Equal(body, testPage) {
+				t.Errorf("解压结果不一致，长度 %d，期望 %d", len(body), len(testPage))
+			}
+		})
+	}
+}
+
+func TestReadBodyKeepsRaw(t *testing.T) {
+	gz := compress(t, EncodingGzip, testPage)
+	tests := []struct {
+		name            string
+		body            []byte
+		contentEncoding string
+		contentType     string
+	}{
+		{"未压缩", testPage, "", "text/html"},
+		{"声明gzip但未压缩", testPage, "gzip", "text/html"},
+		{"gz文件下载", gz, "", "application/gzip"},
+		{"二进制图片", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00"), "", "image/png"},
+		{"空响应体", []byte{}, "gzip", "text/html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, truncated, err := ReadBody(newResponse(tt.body, tt.contentEncoding, tt.contentType), DefaultMaxBody)
+			if err != nil {
+				t.Fatalf("ReadBody 返回错误: %v", err)
+			}
+			if truncated {
+				t.Error("响应体未超过上限，不应标记截断")
+			}
+			if !bytes.Equal(body, tt.body) {
+				t.Errorf("响应体应保持原样，长度 %d，期望 %d", len(body), len(tt.body))
+			}
+		})
+	}
+}
+
+func TestReadBodyTruncates(t *testing.T) {
+	const limit = 100
+	tests := []struct {
+		name            string
+		body            []byte
+		contentEncoding string
+	}{
+		{"未压缩", testPage, ""},
+		{"gzip", compress(t, EncodingGzip, testPage), "gzip"},
+		{"gzip无响应头", compress(t, EncodingGzip, testPage), ""},
+		{"deflate", compress(t, EncodingDeflate, testPage), "deflate"},
+		{"brotli", compress(t, EncodingBrotli, testPage), "br"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, truncated, err := ReadBody(newResponse(tt.body, tt.contentEncoding, "text/html"), limit)
+			if err != nil {
+				t.Fatalf("ReadBody 返回错误: %v", err)
+			}
+			if !truncated {
+				t.Error("解压后超过上限，应标记截断")
+			}
+			if !bytes.Equal(body, testPage[:limit]) {
+				t.Errorf("截断结果不一致: %q", body)
+			}
+		})
+	}
+
+	// 恰好等于上限时不截断
+	body, truncated, err := ReadBody(newResponse(testPage[:limit], "", "text/html"), limit)
+	if err != nil || truncated || len(body) != limit {
+		t.Errorf("恰好等于上限时不应截断，truncated=%v，长度 %d，错误 %v", truncated, len(body), err)
+	}
+}
+
+func TestReadBodyTruncatedStream(t *testing.T) {
+	// 压缩流本身被截断（原始数据超过上限）时，接受已解出的内容
+	big := bytes.Repeat(testPage, 64)
+	gz := compress(t, EncodingGzip, big)
+	limit := int64(len(gz) / 2)
+	body, truncated, err := ReadBody(newResponse(gz, "gzip", "text/html"), limit)
+	if err != nil {
+		t.Fatalf("ReadBody 返回错误: %v", err)
+	}
+	if !truncated {
+		t.Error("原始数据超过上限，应标记截断")
+	}
+	if len(body) == 0 || !bytes.HasPrefix(big, body) {
+		t.Errorf("应返回压缩流截断前解出的内容，长度 %d", len(body))
+	}
+}
@@ -0,0 +1,215 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: charset.go
+    @Date: 2025/6/25 下午3:40*
+*/
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"gxx/utils/logger"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	CharsetUTF8    = "utf-8"
+	CharsetGB18030 = "gb18030" // 无法识别字符集时的默认字符集，兼容 GBK 与 GB2312
+
+	metaSniffSize    = 4096      // 查找 <meta> 字符集声明时检查的字节数
+	chardetSniffSize = 64 * 1024 // 统计识别字符集时检查的字节数
+	chardetMinScore  = 50        // 统计识别结果的最低可信度（1-100），低于该值时使用默认字符集
+)
+
+// metaCharsetRegex 匹配 <meta charset="gbk"> 与 <meta http-equiv="Content-Type" content="text/html; charset=gbk">
+var metaCharsetRegex = regexp.MustCompile(`(?i)<meta[^>]+?charset\s*=\s*["']?\s*([\w.:-]+)`)
+
+// chardetCharsets 统计识别结果中的字符集名称与 htmlindex 名称不一致的映射
+var chardetCharsets = map[string]string{
+	"GB-18030":     CharsetGB18030,
+	"ISO-8859-8-I": "iso-8859-8-i",
+}
+
+// DecodeText 将响应体按字符集解码为 UTF-8 文本，返回文本与使用的字符集；
+// 二进制内容（图片、压缩包、protobuf 等）返回空文本与空字符集
+func DecodeText(body []byte, contentType string) (string, string) {
+	if len(body) == 0 || isBinaryContent(body, contentType) {
+		return "", ""
+	}
+	name := DetectCharset(body, contentType)
+	body = trimBOM(body)
+	if name == CharsetUTF8 {
+		return strings.ToValidUTF8(string(body), string(utf8.RuneError)), name
+	}
+	enc, err := htmlindex.Get(name)
+	if err == nil {
+		var text []byte
+		if text, err = enc.NewDecoder().Bytes(body); err == nil {
+			return string(text), name
+		}
+	}
+	logger.Debug(fmt.Sprintf("响应体按 %s 解码失败: %v", name, err))
+	return strings.ToValidUTF8(string(body), string(utf8.RuneError)), CharsetUTF8
+}
+
+// DetectCharset 识别响应体的字符集，返回 htmlindex 规范名称（如 utf-8、gbk、gb18030、big5、shift_jis、euc-kr）。
+// 优先级：BOM > Content-Type 响应头 > <meta> 声明 > 统计识别；声明的字符集与内容明显不符时以内容为准：
+// 声明 UTF-8 而内容不是合法的 UTF-8，或声明其他字符集而内容是包含非ASCII字符的合法 UTF-8。
+// 统计识别可信度过低时使用 gb18030
+func DetectCharset(body []byte, contentType string) string {
+	if name := bomCharset(body); name != "" {
+		return name
+	}
+	valid := utf8.Valid(body)
+	for _, declared := range []string{headerCharset(contentType), metaCharset(body)} {
+		if declared == "" {
+			continue
+		}
+		if declared == CharsetUTF8 {
+			if valid {
+				return declared
+			}
+			continue
+		}
+		if valid && hasNonASCII(body) {
+			return CharsetUTF8
+		}
+		return declared
+	}
+	if valid {
+		return CharsetUTF8
+	}
+	return guessCharset(body, contentType)
+}
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16BEBOM = []byte{0xfe, 0xff}
+	utf16LEBOM = []byte{0xff, 0xfe}
+)
+
+// bomCharset 根据 BOM 识别字符集
+func bomCharset(body []byte) string {
+	switch {
+	case bytes.HasPrefix(body, utf8BOM):
+		return CharsetUTF8
+	case bytes.HasPrefix(body, utf16BEBOM):
+		return "utf-16be"
+	case bytes.HasPrefix(body, utf16LEBOM):
+		return "utf-16le"
+	}
+	return ""
+}
+
+// trimBOM 去除内容开头的 BOM
+func trimBOM(body []byte) []byte {
+	for _, bom := range [][]byte{utf8BOM, utf16BEBOM, utf16LEBOM} {
+		if bytes.HasPrefix(body, bom) {
+			return body[len(bom):]
+		}
+	}
+	return body
+}
+
+// headerCharset 返回 Content-Type 中声明的字符集，未声明或无法识别时返回空
+func headerCharset(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return canonicalCharset(params["charset"])
+}
+
+// metaCharset 返回HTML开头 <meta> 标签中声明的字符集，未声明或无法识别时返回空
+func metaCharset(body []byte) string {
+	if len(body) > metaSniffSize {
+		body = body[:metaSniffSize]
+	}
+	match := metaCharsetRegex.FindSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	return canonicalCharset(string(match[1]))
+}
+
+// canonicalCharset 返回字符集标签的 htmlindex 规范名称，如 gb2312 → gbk、x-sjis → shift_jis，无法识别时返回空
+func canonicalCharset(label string) string {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return ""
+	}
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return ""
+	}
+	name, err := htmlindex.Name(enc)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// guessCharset 统计识别非UTF-8内容的字符集，HTML 内容识别时忽略标签
+func guessCharset(body []byte, contentType string) string {
+	if len(body) > chardetSniffSize {
+		body = body[:chardetSniffSize]
+	}
+	detector := chardet.NewTextDetector()
+	if strings.Contains(strings.ToLower(contentType), "html") || metaCharsetRegex.Match(body) || bytes.Contains(bytes.ToLower(body), []byte("<html")) {
+		detector = chardet.NewHtmlDetector()
+	}
+	result, err := detector.DetectBest(body)
+	if err != nil || result.Confidence < chardetMinScore {
+		return CharsetGB18030
+	}
+	label := result.Charset
+	if mapped, ok := chardetCharsets[label]; ok {
+		label = mapped
+	}
+	if name := canonicalCharset(label); name != "" && name != CharsetUTF8 {
+		logger.Debug(fmt.Sprintf("统计识别字符集：%s，可信度：%d", name, result.Confidence))
+		return name
+	}
+	return CharsetGB18030
+}
+
+// hasNonASCII 判断内容是否包含非ASCII字符
+func hasNonASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinaryContent 判断响应体是否为二进制内容：Content-Type 为非文本类型，或未声明 Content-Type 且内容不像文本（UTF-16 除外）
+func isBinaryContent(body []byte, contentType string) bool {
+	if bomCharset(body) != "" {
+		return false
+	}
+	if strings.TrimSpace(contentType) != "" {
+		if isTextType(contentType) {
+			return false
+		}
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		// 部分服务端对HTML页面返回 application/octet-stream 等类型，内容像文本时仍然解码
+		switch {
+		case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+			strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "font/"):
+			return true
+		}
+	}
+	return !looksLikeText(body)
+}
@@ -0,0 +1,151 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: conninfo.go
+    @Date: 2025/6/14 下午3:10*
+*/
+package network
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"gxx/utils/proto"
+	"net"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// 连接的传输类型，对应 proto.AddrType 的 transport 字段
+const (
+	TransportHttp = "http"
+	TransportTcp  = "tcp"
+	TransportUdp  = "udp"
+)
+
+// ConnInfo 记录HTTP请求实际使用的连接地址，发生重定向时记录最后一次连接
+type ConnInfo struct {
+	mu     sync.Mutex
+	local  net.Addr
+	remote net.Addr
+}
+
+// NewConnInfo 创建连接信息记录
+func NewConnInfo() *ConnInfo {
+	return &ConnInfo{}
+}
+
+// WithContext 将 httptrace 挂载到请求上下文，ConnInfo 为空时原样返回
+func (c *ConnInfo) WithContext(ctx context.Context) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Conn == nil {
+				return
+			}
+			c.mu.Lock()
+			c.local = info.Conn.LocalAddr()
+			c.remote = info.Conn.RemoteAddr()
+			c.mu.Unlock()
+		},
+	})
+}
+
+// ToProto 转换为 proto.ConnInfoType，state 为响应的TLS连接状态，可以为空
+func (c *ConnInfo) ToProto(state *tls.ConnectionState) *proto.ConnInfoType {
+	info := &proto.ConnInfoType{Tls: TLS2Proto(state)}
+	if c == nil {
+		return info
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info.Source = Addr2Proto(c.local, TransportHttp)
+	info.Destination = Addr2Proto(c.remote, TransportHttp)
+	return info
+}
+
+// ConnInfoFromConn 根据TCP/UDP连接构造 proto.ConnInfoType，TLS连接同时记录握手信息
+func ConnInfoFromConn(conn net.Conn, transport string) *proto.ConnInfoType {
+	info := &proto.ConnInfoType{Tls: &proto.TlsType{}}
+	if conn == nil {
+		return info
+	}
+	info.Source = Addr2Proto(conn.LocalAddr(), transport)
+	info.Destination = Addr2Proto(conn.RemoteAddr(), transport)
+	if state := ConnTLSState(conn); state != nil {
+		info.Tls = TLS2Proto(state)
+	}
+	return info
+}
+
+// ConnInfoFromURL 无法获取实际连接时（如raw请求）根据连接地址尽量填充目的地址信息：只包含端口，地址为IP时包含IP，
+// 不重新解析域名（实际连接可能使用代理或不同的解析结果），源地址与TLS信息为空。
+// raw客户端内部建立连接且不提供按请求的拨号回调，因此raw请求的连接信息只能是部分信息
+func ConnInfoFromURL(target string) *proto.ConnInfoType {
+	info := &proto.ConnInfoType{Source: &proto.AddrType{Transport: TransportHttp}, Tls: &proto.TlsType{}}
+	u, err := url.Parse(target)
+	if err != nil {
+		return info
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	info.Destination = &proto.AddrType{Transport: TransportHttp, Port: port}
+	if ip := u.Hostname(); net.ParseIP(ip) != nil {
+		info.Destination.Ip = ip
+		info.Destination.Addr = net.JoinHostPort(ip, port)
+	}
+	return info
+}
+
+// Addr2Proto 将网络地址转换为 proto.AddrType
+func Addr2Proto(addr net.Addr, transport string) *proto.AddrType {
+	result := &proto.AddrType{Transport: transport}
+	if addr == nil {
+		return result
+	}
+	result.Addr = addr.String()
+	if host, port, err := net.SplitHostPort(result.Addr); err == nil {
+		result.Ip = host
+		result.Port = port
+	}
+	return result
+}
+
+// TLS2Proto 将TLS连接状态转换为 proto.TlsType，state 为空时返回空的TLS信息
+func TLS2Proto(state *tls.ConnectionState) *proto.TlsType {
+	if state == nil {
+		return &proto.TlsType{}
+	}
+	info := &proto.TlsType{
+		Version:    tls.VersionName(state.Version),
+		Cipher:     tls.CipherSuiteName(state.CipherSuite),
+		Alpn:       state.NegotiatedProtocol,
+		ServerName: state.ServerName,
+	}
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		sum := sha256.Sum256(cert.Raw)
+		info.Certificate = &proto.CertType{
+			Subject:    cert.Subject.String(),
+			Issuer:     cert.Issuer.String(),
+			CommonName: cert.Subject.CommonName,
+			DnsNames:   cert.DNSNames,
+			NotBefore:  cert.NotBefore.Unix(),
+			NotAfter:   cert.NotAfter.Unix(),
+			Serial:     cert.SerialNumber.Text(16),
+			Sha256:     hex.EncodeToString(sum[:]),
+		}
+	}
+	return info
+}
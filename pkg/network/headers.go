@@ -0,0 +1,148 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: headers.go
+    @Date: 2025/6/19 下午4:20*
+*/
+package network
+
+import (
+	"fmt"
+	"gxx/utils/common"
+	"net/http"
+	"strings"
+)
+
+// 请求头预设
+const (
+	HeaderProfileDefault = "default" // 随机User-Agent与兼容旧版浏览器的Accept
+	HeaderProfileChrome  = "chrome"  // 新版Chrome浏览器
+	HeaderProfileCurl    = "curl"    // curl命令行
+	HeaderProfileNone    = "none"    // 不添加任何请求头，也不伪造XFF与Cookie
+)
+
+const (
+	chromeUA     = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+	curlUA       = "curl/8.7.1"
+	legacyAccept = "application/x-shockwave-flash, image/gif, image/x-xbitmap, image/jpeg, image/pjpeg, application/vnd.ms-excel, application/vnd.ms-powerpoint, application/msword, */*"
+)
+
+var (
+	HeaderProfile = HeaderProfileDefault // 请求头预设
+	DisableSpoof  = false                // 禁用随机 X-Forwarded-For 与随机 Cookie
+	GlobalHeaders = http.Header{}        // 全局请求头，优先级高于预设，低于规则中的请求头
+)
+
+// SetHeaderProfile 设置请求头预设，name 为空时使用 default
+func SetHeaderProfile(name string) error {
+	switch name = strings.ToLower(name); name {
+	case "":
+		HeaderProfile = HeaderProfileDefault
+	case HeaderProfileDefault, HeaderProfileChrome, HeaderProfileCurl, HeaderProfileNone:
+		HeaderProfile = name
+	default:
+		return fmt.Errorf("未知的请求头预设: %s，可选：%s、%s、%s、%s", name,
+			HeaderProfileDefault, HeaderProfileChrome, HeaderProfileCurl, HeaderProfileNone)
+	}
+	return nil
+}
+
+// SetGlobalHeaders 设置全局请求头，lines 格式为 "Name: value"，cookie 不为空时作为 Cookie 请求头
+func SetGlobalHeaders(lines []string, cookie string) error {
+	headers := http.Header{}
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("请求头格式错误: %s，应为 \"Name: value\"", line)
+		}
+		headers.Set(name, strings.TrimSpace(value))
+	}
+	if cookie != "" {
+		headers.Set("Cookie", cookie)
+	}
+	GlobalHeaders = headers
+	return nil
+}
+
+// profileHeaders 返回请求头预设对应的请求头
+func profileHeaders() map[string]string {
+	switch HeaderProfile {
+	case HeaderProfileChrome:
+		return map[string]string{
+			"User-Agent":                chromeUA,
+			"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+			"Accept-Encoding":           AcceptEncoding,
+			"Accept-Language":           "zh-CN,zh;q=0.9,en;q=0.8",
+			"Sec-Ch-Ua":                 `"Google Chrome";v="131", "Chromium";v="131", "Not_A Brand";v="24"`,
+			"Sec-Ch-Ua-Mobile":          "?0",
+			"Sec-Ch-Ua-Platform":        `"Windows"`,
+			"Sec-Fetch-Dest":            "document",
+			"Sec-Fetch-Mode":            "navigate",
+			"Sec-Fetch-Site":            "none",
+			"Sec-Fetch-User":            "?1",
+			"Upgrade-Insecure-Requests": "1",
+		}
+	case HeaderProfileCurl:
+		return map[string]string{
+			"User-Agent": curlUA,
+			"Accept":     "*/*",
+		}
+	case HeaderProfileNone:
+		// User-Agent 为空时 net/http 不发送默认的 Go-http-client
+		return map[string]string{"User-Agent": ""}
+	default:
+		return map[string]string{
+			"User-Agent":      common.RandomUA(),
+			"Accept":          legacyAccept,
+			"Accept-Encoding": AcceptEncoding,
+			"Pragma":          "no-cache",
+			"Cache-Control":   "no-cache",
+		}
+	}
+}
+
+// spoofEnabled 是否伪造 X-Forwarded-For 与 Cookie
+func spoofEnabled() bool {
+	return !DisableSpoof && HeaderProfile != HeaderProfileNone
+}
+
+// applyIdentity 按预设、伪造与全局请求头设置请求头，session 为true时由cookie jar负责Cookie，不再注入随机cookie
+func applyIdentity(header http.Header, session bool) {
+	for k, v := range profileHeaders() {
+		header.Set(k, v)
+	}
+	if spoofEnabled() {
+		header.Set("X-Forwarded-For", common.GetRandomIP())
+		if !session {
+			header.Set("Cookie", "cookie="+common.RandomString(15))
+		}
+	}
+	for k, v := range GlobalHeaders {
+		header[k] = append([]string(nil), v...)
+	}
+}
+
+// ApplyGlobalHeaders 为raw请求补充全局请求头，raw请求中已存在的请求头不覆盖
+func ApplyGlobalHeaders(headers map[string][]string) map[string][]string {
+	if len(GlobalHeaders) == 0 {
+		return headers
+	}
+	if headers == nil {
+		headers = make(map[string][]string, len(GlobalHeaders))
+	}
+	for k, v := range GlobalHeaders {
+		exists := false
+		for name := range headers {
+			if strings.EqualFold(name, k) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			headers[k] = append([]string(nil), v...)
+		}
+	}
+	return headers
+}
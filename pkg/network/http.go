@@ -22,8 +22,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/chainreactors/proxyclient"
-
 	"github.com/zan8in/retryablehttp"
 	"golang.org/x/net/context"
 )
@@ -33,26 +31,37 @@ var (
 	RetryClient    *retryablehttp.Client // 可处理重定向的客户端
 	tlsConfig      *tls.Config           // tls配置
 	clientInitOnce sync.Once             // 确保客户端只初始化一次
-	transportCache sync.Map              // 缓存Transport对象，避免重复创建
+	transportCache sync.Map              // 按代理与目标缓存连接池，避免重复创建
+
+	MaxDefaultBody = DefaultMaxBody // 响应体读取上限，默认512KB，可通过 -max-body 或配置文件修改
+	MaxRedirects   = 5              // 最大重定向次数，可通过配置文件修改
+
+	DisableKeepAlives = false // 禁用连接复用，每个请求使用新连接
+	DisableHTTP2      = false // 禁用 HTTPS 目标的 HTTP/2 协商
+	EnableH2C         = false // 探测 HTTP 目标是否支持 h2c（HTTP/2 明文）
 )
 
 // 全局客户端配置
 const (
-	MaxDefaultBody int64 = 512 * 1024       // 512KB
-	DefaultTimeout       = 10 * time.Second // 默认请求超时时间
-	HttpPrefix           = "http://"        // HTTP协议前缀
-	HttpsPrefix          = "https://"       // HTTPS协议前缀
-	maxRedirects         = 5                // 最大重定向次数
+	DefaultTimeout = 10 * time.Second // 默认请求超时时间
+	HttpPrefix     = "http://"        // HTTP协议前缀
+	HttpsPrefix    = "https://"       // HTTPS协议前缀
 )
 
 // OptionsRequest 请求配置参数结构体
 type OptionsRequest struct {
 	Proxy              string            // 代理地址，格式：scheme://host:port
 	Timeout            time.Duration     // 请求超时时间（默认5秒）
-	Retries            int               // 最大重试次数（默认3次）
+	Retries            int               // 最大尝试次数（含首次请求），为0时使用全局重试策略
 	FollowRedirects    bool              // 是否跟随重定向（默认true）
 	InsecureSkipVerify bool              // 是否跳过SSL证书验证（默认true）
 	CustomHeaders      map[string]string // 自定义请求头
+	CookieJar          http.CookieJar    // 会话cookie jar，为空时不保持会话
+	Timing             *Timing           // 请求耗时记录，为空时不记录
+	ConnInfo           *ConnInfo         // 连接地址记录，为空时不记录
+	TLSFingerprint     string            // TLS客户端指纹，为空时使用全局配置
+	VHost              string            // 虚拟主机，设置后作为 Host 与 SNI，为空时使用目标地址
+	Errors             *ErrorStats       // 请求失败记录，为空时不记录
 }
 
 // 初始化全局客户端实例
@@ -91,14 +100,11 @@ func initGlobalClient() {
 	opts := retryablehttp.DefaultOptionsSingle
 	opts.Timeout = DefaultTimeout
 
-	transport := &http.Transport{
-		TLSClientConfig:   tlsConfig,
-		DisableKeepAlives: true, // 禁用连接复用，避免"Unsolicited response"错误
-	}
-
 	RetryClient = retryablehttp.NewClient(opts)
-	RetryClient.HTTPClient.Transport = transport
-	RetryClient.HTTPClient2.Transport = transport
+	if transport, err := createTransport("", "", "", ""); err == nil {
+		RetryClient.HTTPClient.Transport = transport
+		RetryClient.HTTPClient2.Transport = transport
+	}
 }
 
 // NewRequestHttp 创建并发送HTTP请求
@@ -108,8 +114,13 @@ func NewRequestHttp(urlStr string, options OptionsRequest) (*http.Response, erro
 		logger.Debug(fmt.Sprintf("使用代理：%s", options.Proxy))
 	}
 
+	release := AcquireHostSlot(urlStr)
+	defer release()
 	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
 	defer cancel()
+	ctx = options.Timing.WithContext(ctx)
+	ctx = options.ConnInfo.WithContext(ctx)
+	ctx = options.Errors.WithContext(ctx)
 
 	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
@@ -117,9 +128,9 @@ func NewRequestHttp(urlStr string, options OptionsRequest) (*http.Response, erro
 	}
 	configureHeaders(req, options)
 
-	client := configureClient(options)
+	client := configureClient(urlStr, options)
 
-	return client.Do(req)
+	return doRequest(client, req, options.Errors)
 }
 
 // SendRequestHttp yaml poc or 指纹 yaml 构建发送http请求
@@ -128,15 +139,29 @@ func SendRequestHttp(ctx context.Context, Method string, UrlStr string, Body str
 	if options.Proxy != "" {
 		logger.Debug(fmt.Sprintf("使用代理：%s", options.Proxy))
 	}
+	ctx = options.Timing.WithContext(ctx)
+	ctx = options.ConnInfo.WithContext(ctx)
+	ctx = options.Errors.WithContext(ctx)
 	req, err := retryablehttp.NewRequestWithContext(ctx, Method, UrlStr, Body)
 	if err != nil {
 		return nil, err
 	}
 	configureHeaders(req, options)
 
-	client := configureClient(options)
+	client := configureClient(UrlStr, options)
 
-	return client.Do(req)
+	return doRequest(client, req, options.Errors)
+}
+
+// doRequest 按重试策略发送请求，重试耗尽后仍失败或最终响应为5xx时记录最终的错误分类
+func doRequest(client *retryablehttp.Client, req *retryablehttp.Request, errs *ErrorStats) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		errs.Fail(ClassifyError(err))
+	} else {
+		errs.Fail(ClassifyStatus(resp.StatusCode))
+	}
+	return resp, err
 }
 
 // setDefaults 设置配置参数的默认值
@@ -146,28 +171,20 @@ func setDefaults(options *OptionsRequest) {
 	}
 
 	if options.Retries == 0 {
-		options.Retries = 3
+		options.Retries = Retry.MaxAttempts
 	}
 
 	// 默认启用忽略TLS证书验证
 	options.InsecureSkipVerify = true
 }
 
-// configureHeaders 配置请求头信息
+// configureHeaders 配置请求头信息，优先级：请求头预设 < 全局请求头 < 规则中的请求头
 func configureHeaders(req *retryablehttp.Request, options OptionsRequest) {
-	// 设置通用请求头
-	headers := map[string]string{
-		"User-Agent":      common.RandomUA(),
-		"Accept":          "application/x-shockwave-flash, image/gif, image/x-xbitmap, image/jpeg, image/pjpeg, application/vnd.ms-excel, application/vnd.ms-powerpoint, application/msword, */*",
-		"X-Forwarded-For": common.GetRandomIP(),
-		"Pragma":          "no-cache",
-		"Cookie":          "cookie=" + common.RandomString(15),
-		"Cache-Control":   "no-cache",
-		"Connection":      "close", // 确保每次请求后不保持连接
-	}
-
-	for k, v := range headers {
-		req.Header.Set(k, v)
+	// 启用会话时由cookie jar负责Cookie，不再注入随机cookie
+	applyIdentity(req.Header, options.CookieJar != nil)
+	// 禁用连接复用时通知服务端关闭连接
+	if DisableKeepAlives {
+		req.Header.Set("Connection", "close")
 	}
 
 	// 默认POST内容类型
@@ -175,77 +192,48 @@ func configureHeaders(req *retryablehttp.Request, options OptionsRequest) {
 		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	}
 
-	// 添加自定义headers
+	// 添加自定义headers，值为空时不发送该请求头
 	for key, value := range options.CustomHeaders {
-		req.Header.Set(key, value)
-	}
-}
-
-// createTransport 创建传输层
-func createTransport(proxyURL string) (*http.Transport, error) {
-	// 检查缓存中是否已存在相同配置的transport
-	if cachedTransport, found := transportCache.Load(proxyURL); found {
-		return cachedTransport.(*http.Transport), nil
-	}
-
-	var transport *http.Transport
-
-	if proxyURL == "" {
-		transport = &http.Transport{
-			TLSClientConfig:     tlsConfig,
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-			DisableKeepAlives:   true, // 禁用连接复用，避免"Unsolicited response"错误
-		}
-	} else {
-		proxy, err := url.Parse(proxyURL)
-		if err != nil {
-			return nil, fmt.Errorf("代理地址解析失败: %v", err)
-		}
-
-		dialer, err := proxyclient.NewClient(proxy)
-		if err != nil {
-			return nil, fmt.Errorf("创建代理客户端失败: %v", err)
-		}
-
-		transport = &http.Transport{
-			DialContext:         dialer.DialContext,
-			TLSClientConfig:     tlsConfig,
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-			DisableKeepAlives:   true, // 禁用连接复用，避免"Unsolicited response"错误
+		if value == "" {
+			req.Header.Del(key)
+			if strings.EqualFold(key, "User-Agent") {
+				req.Header.Set(key, "")
+			}
+			continue
 		}
+		req.Header.Set(key, value)
 	}
-
-	// 存入缓存
-	transportCache.Store(proxyURL, transport)
-
-	return transport, nil
 }
 
 // configureClient 配置HTTP客户端参数
-func configureClient(options OptionsRequest) *retryablehttp.Client {
+func configureClient(urlStr string, options OptionsRequest) *retryablehttp.Client {
 	if RetryClient == nil {
 		logger.Error("RetryClient 未初始化")
 		initGlobalClient() // 初始化并恢复执行
 	}
 
-	// 创建新的客户端实例以避免修改全局设置
+	// 创建新的客户端实例以避免修改全局设置，按全局重试策略与请求的最大尝试次数重试
+	policy := Retry
+	policy.MaxAttempts = options.Retries
+	// opts.Timeout 为全部重试的总时长，单次请求的超时在下方设置为 options.Timeout
 	opts := retryablehttp.DefaultOptionsSingle
-	opts.Timeout = options.Timeout
+	opts.Timeout = policy.Budget(options.Timeout)
+	opts.RetryMax = policy.attempts() - 1
+	opts.CheckRetry = policy.checkRetry()
+	opts.Backoff = policy.backoff()
 
-	// 创建新的客户端
+	// 创建新的客户端，重试耗尽时返回最后一次的响应（如5xx）而不是错误
 	client := retryablehttp.NewClient(opts)
+	client.ErrorHandler = retryablehttp.PassthroughErrorHandler
 
-	// 配置传输层
-	transport, err := createTransport(options.Proxy)
+	// 配置传输层，每个目标使用独立的连接池
+	transport, err := createTransport(options.Proxy, urlStr, options.TLSFingerprint, options.VHost)
 	if err != nil {
 		logger.Error("创建传输层失败: %v", err)
 	} else {
-		client.HTTPClient.Transport = transport
-		client.HTTPClient2.Transport = transport
+		// 经过速率限制器发送，重试与重定向同样受限
+		client.HTTPClient.Transport = &limitTransport{base: transport}
+		client.HTTPClient2.Transport = &limitTransport{base: transport}
 	}
 
 	// 配置超时
@@ -253,22 +241,26 @@ func configureClient(options OptionsRequest) *retryablehttp.Client {
 	client.HTTPClient2.Timeout = options.Timeout
 
 	// 配置重定向策略
-	redirectPolicy := createRedirectPolicy(options.FollowRedirects)
+	redirectPolicy := createRedirectPolicy(options.FollowRedirects, options.CookieJar == nil)
 	client.HTTPClient.CheckRedirect = redirectPolicy
 	client.HTTPClient2.CheckRedirect = redirectPolicy
 
+	// 配置会话cookie jar，重定向过程中的Set-Cookie由jar自动保存
+	client.HTTPClient.Jar = options.CookieJar
+	client.HTTPClient2.Jar = options.CookieJar
+
 	return client
 }
 
-// createRedirectPolicy 创建重定向策略
-func createRedirectPolicy(followRedirects bool) func(*http.Request, []*http.Request) error {
+// createRedirectPolicy 创建重定向策略，copyCookies为true时手动携带之前响应的Set-Cookie（未使用cookie jar时）
+func createRedirectPolicy(followRedirects bool, copyCookies bool) func(*http.Request, []*http.Request) error {
 	return func(req *http.Request, via []*http.Request) error {
 		if !followRedirects {
 			return http.ErrUseLastResponse // 禁止重定向
 		}
 
 		// 从之前的响应中获取Set-Cookie并添加到请求中
-		if len(via) > 0 {
+		if copyCookies && len(via) > 0 {
 			for _, prevReq := range via {
 				if prevReq.Response != nil && len(prevReq.Response.Header["Set-Cookie"]) > 0 {
 					for _, cookie := range prevReq.Response.Cookies() {
@@ -279,8 +271,8 @@ func createRedirectPolicy(followRedirects bool) func(*http.Request, []*http.Requ
 		}
 
 		// 限制最大重定向次数
-		if len(via) >= maxRedirects {
-			return fmt.Errorf("达到最大重定向次数: %d", maxRedirects)
+		if len(via) >= MaxRedirects {
+			return fmt.Errorf("达到最大重定向次数: %d", MaxRedirects)
 		}
 
 		return nil
@@ -323,14 +315,11 @@ func simpleRetryHttpGet(target string, proxy string, timeout int32) ([]byte, int
 	}
 
 	// 配置传输层
-	transport, err := createTransport(proxy)
+	transport, err := createTransport(proxy, target, "", "")
 	if err == nil {
 		client.HTTPClient.Transport = transport
 	}
 
-	// 添加连接关闭头，确保每次请求后不保持连接
-	req.Header.Set("Connection", "close")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		if resp != nil {
@@ -345,8 +334,7 @@ func simpleRetryHttpGet(target string, proxy string, timeout int32) ([]byte, int
 		_ = Body.Close()
 	}(resp.Body)
 
-	reader := io.LimitReader(resp.Body, MaxDefaultBody)
-	respBody, err := io.ReadAll(reader)
+	respBody, _, err := ReadBody(resp, MaxDefaultBody)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -354,33 +342,6 @@ func simpleRetryHttpGet(target string, proxy string, timeout int32) ([]byte, int
 	return respBody, resp.StatusCode, nil
 }
 
-// CheckProtocol 检查网络通信协议
-func CheckProtocol(host string, proxy string) (string, error) {
-	if len(strings.TrimSpace(host)) == 0 {
-		return "", fmt.Errorf("host %q is empty", host)
-	}
-
-	if strings.HasPrefix(host, HttpPrefix) || strings.HasPrefix(host, HttpsPrefix) {
-		return host, nil
-	}
-
-	u, err := url.Parse(HttpPrefix + host)
-	if err != nil {
-		return "", err
-	}
-
-	switch u.Port() {
-	case "80":
-		return checkAndReturnProtocol(HttpPrefix+host, proxy)
-	case "443":
-		return checkAndReturnProtocol(HttpsPrefix+host, proxy)
-	default:
-		if result, err := checkAndReturnProtocol(HttpsPrefix+host, proxy); err == nil {
-			return result, nil
-		}
-		return checkAndReturnProtocol(HttpPrefix+host, proxy)
-	}
-}
 func CheckProtocolGet(target string, proxy string, timeout int) (string, error) {
 	client := RetryClient
 	if client == nil {
@@ -399,7 +360,7 @@ func CheckProtocolGet(target string, proxy string, timeout int) (string, error)
 	if err != nil {
 		return "", nil
 	}
-	req.Header.Set("User-Agent", common.RandomUA())
+	applyIdentity(req.Header, false)
 
 	// 禁用重定向
 	client.HTTPClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
@@ -407,12 +368,12 @@ func CheckProtocolGet(target string, proxy string, timeout int) (string, error)
 	}
 
 	// 配置传输层
-	transport, err := createTransport(proxy)
+	transport, err := createTransport(proxy, target, "", "")
 	if err == nil {
 		client.HTTPClient.Transport = transport
 	}
 
-	// 添加连接关闭头，确保每次请求后不保持连接
+	// 部分服务端会对HEAD请求返回响应体，不复用探测连接
 	req.Header.Set("Connection", "close")
 
 	resp, err := client.Do(req)
@@ -429,41 +390,6 @@ func CheckProtocolGet(target string, proxy string, timeout int) (string, error)
 	return "http", nil
 }
 
-func checkAndReturnProtocol(url string, proxy string) (string, error) {
-	// 优化：添加协议前缀检查
-	if strings.HasPrefix(url, HttpsPrefix) {
-		return url, nil
-	}
-
-	// 优化：添加参数有效性检查
-	if url == "" {
-		return "", errors.New("URL不能为空")
-	}
-
-	res, err := CheckProtocolGet(url, proxy, 0)
-	if err != nil {
-		// 优化：添加更具体的错误信息
-		return "", fmt.Errorf("检查协议失败: %w", err)
-	}
-
-	// 判断是否为HTTPS协议
-	if res == "https" {
-		// 只有当URL以HTTP前缀开始时才进行替换
-		if strings.HasPrefix(url, HttpPrefix) {
-			return HttpsPrefix + url[len(HttpPrefix):], nil
-		}
-		// 如果没有前缀，则添加HTTPS前缀
-		return HttpsPrefix + url, nil
-	}
-
-	// 如果是HTTP协议但没有前缀，则添加HTTP前缀
-	if !strings.HasPrefix(url, HttpPrefix) {
-		return HttpPrefix + url, nil
-	}
-
-	return url, nil
-}
-
 // Url2ProtoUrl URL转换为Proto URL
 func Url2ProtoUrl(u *url.URL) *proto.UrlType {
 	return &proto.UrlType{
@@ -477,6 +403,61 @@ func Url2ProtoUrl(u *url.URL) *proto.UrlType {
 	}
 }
 
+// Header2Proto 将响应头转换为proto格式，headers 中同名头只保留第一个值（Set-Cookie 的 Expires 等值本身包含逗号，合并后无法区分），
+// header_values 保留全部值
+func Header2Proto(header http.Header) (map[string]string, map[string]*proto.HeaderValues) {
+	headers := make(map[string]string, len(header))
+	headerValues := make(map[string]*proto.HeaderValues, len(header))
+	for k, v := range header {
+		k = strings.ToLower(k)
+		if hv, ok := headerValues[k]; ok {
+			hv.Values = append(hv.Values, v...)
+		} else {
+			headerValues[k] = &proto.HeaderValues{Values: append([]string(nil), v...)}
+		}
+		if _, ok := headers[k]; !ok && len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+	return headers, headerValues
+}
+
+// Cookies2Proto 解析响应头中的全部 Set-Cookie，返回cookie名称到值的映射以及完整属性
+func Cookies2Proto(header http.Header) (map[string]string, map[string]*proto.CookieType) {
+	cookies := make(map[string]string)
+	attributes := make(map[string]*proto.CookieType)
+	for _, line := range header.Values("Set-Cookie") {
+		c, err := http.ParseSetCookie(line)
+		if err != nil {
+			continue
+		}
+		cookie := &proto.CookieType{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			MaxAge:   int64(c.MaxAge),
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			Raw:      line,
+		}
+		if !c.Expires.IsZero() {
+			cookie.Expires = c.Expires.Unix()
+		}
+		switch c.SameSite {
+		case http.SameSiteLaxMode:
+			cookie.SameSite = "Lax"
+		case http.SameSiteStrictMode:
+			cookie.SameSite = "Strict"
+		case http.SameSiteNoneMode:
+			cookie.SameSite = "None"
+		}
+		cookies[c.Name] = c.Value
+		attributes[c.Name] = cookie
+	}
+	return cookies, attributes
+}
+
 // ParseRequest 解析请求raw数据包
 func ParseRequest(oReq *http.Request) (*proto.Request, error) {
 	req := &proto.Request{
@@ -504,34 +485,3 @@ func ParseRequest(oReq *http.Request) (*proto.Request, error) {
 
 	return req, nil
 }
-
-// cleanupTransportCache 定期清理transport缓存
-func cleanupTransportCache() {
-	ticker := time.NewTicker(5 * time.Minute) // 更频繁清理
-	defer ticker.Stop()
-
-	for range ticker.C {
-		// 遍历所有缓存的transport并关闭空闲连接
-		transportCache.Range(func(key, value interface{}) bool {
-			if transport, ok := value.(*http.Transport); ok {
-				transport.CloseIdleConnections()
-			}
-			return true
-		})
-
-		// 记录清理日志
-		logger.Debug("已清理transport缓存中的空闲连接")
-
-		// 如果缓存过大，可以考虑完全重置
-		var count int
-		transportCache.Range(func(_, _ interface{}) bool {
-			count++
-			return true
-		})
-		// 如果缓存项超过50个，重置缓存
-		if count > 100 {
-			transportCache = sync.Map{}
-			logger.Debug("transport缓存过大，已重置")
-		}
-	}
-}
@@ -19,6 +19,7 @@ import (
 	"net"
 	"net/url"
 
+	"golang.org/x/net/context"
 	"golang.org/x/net/proxy"
 )
 
@@ -27,23 +28,23 @@ const (
 	DefaultDialTimeout  = 5 * time.Second
 	DefaultWriteTimeout = 5 * time.Second
 	DefaultReadTimeout  = 5 * time.Second
-	DefaultRetryDelay   = 2 * time.Second
 	DefaultReadSize     = 2048
-	DefaultMaxRetries   = 3
 )
 
 // TcpOrUdpConfig 配置结构体
 type TcpOrUdpConfig struct {
-	Network      string        // 网络类型，TCP 或 UDP
-	MaxRetries   int           // 最大重试次数
-	ReadSize     int           // 读取数据的缓冲区大小
-	DialTimeout  time.Duration // 连接超时时间
-	WriteTimeout time.Duration // 写入超时时间
-	ReadTimeout  time.Duration // 读取超时时间
-	RetryDelay   time.Duration // 重试延迟时间
-	ProxyURL     string        // 代理URL
-	IsLts        bool          // 是否发送LTS请求
-	ServerName   string        // ServerName对tls请求的配置
+	Network        string        // 网络类型，TCP 或 UDP
+	MaxRetries     int           // 建立连接的最大尝试次数（含首次连接），为0时使用全局重试策略
+	ReadSize       int           // 读取数据的缓冲区大小
+	DialTimeout    time.Duration // 连接超时时间
+	WriteTimeout   time.Duration // 写入超时时间
+	ReadTimeout    time.Duration // 读取超时时间
+	RetryDelay     time.Duration // 首次重试的退避时间，为0时使用全局重试策略
+	ProxyURL       string        // 代理URL，支持代理列表或代理文件
+	IsLts          bool          // 是否发送LTS请求
+	ServerName     string        // ServerName对tls请求的配置
+	TLSFingerprint string        // TLS客户端指纹，为空时使用全局配置
+	Errors         *ErrorStats   // 连接与发送失败记录，为空时不记录
 }
 
 // Client 客户端结构体
@@ -51,6 +52,9 @@ type Client struct {
 	address string
 	conn    net.Conn
 	conf    TcpOrUdpConfig
+	timing  *Timing // 连接、首字节及总耗时
+	release func()  // 释放速率限制器的并发槽位
+	pending []byte  // ReadUntil 多读取的数据，由后续读取优先返回
 }
 
 // parseAddress 解析地址，确保包含端口号
@@ -93,66 +97,110 @@ func NewClient(address string, conf TcpOrUdpConfig) (*Client, error) {
 		conf.DialTimeout = DefaultDialTimeout
 	}
 
-	if conf.RetryDelay == 0 {
-		conf.RetryDelay = DefaultRetryDelay
+	if len(conf.Network) == 0 {
+		conf.Network = DefaultNetwork
 	}
 
-	if conf.MaxRetries == 0 {
-		conf.MaxRetries = DefaultMaxRetries
+	fingerprint, err := ResolveTLSFingerprint(conf.TLSFingerprint)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(conf.Network) == 0 {
-		conf.Network = DefaultNetwork
+	// 创建Dialer，ProxyURL 为代理列表时按代理池策略选择
+	dialer, selected, err := newDialer(conf.ProxyURL, address, conf.DialTimeout)
+	if err != nil {
+		return nil, err
 	}
 
-	// 创建Dialer
-	var dialer proxy.Dialer = &net.Dialer{Timeout: conf.DialTimeout}
+	// 等待速率限制，连接关闭时释放并发槽位
+	release, err := Limiter.Acquire(context.Background(), HostKey(address))
+	if err != nil {
+		return nil, err
+	}
 
-	// 处理代理
-	if conf.ProxyURL != "" {
-		proxyURL, err := url.Parse(conf.ProxyURL)
-		if err != nil {
-			return nil, fmt.Errorf("invalid proxy URL: %w", err)
-		}
-		dialer, err = proxyclient.NewClient(proxyURL)
-		//dialer, err = proxy.FromURL(proxyURL, proxy.Direct)
+	// 配置了 -resolve 或 -resolver 时在本地解析目标主机名
+	dial := resolveDial(func(_ context.Context, network, addr string) (net.Conn, error) {
+		return dialer.Dial(network, addr)
+	})
+
+	// 按重试策略建立连接，连接失败与TLS握手失败时退避后重新连接
+	policy := Retry
+	if conf.MaxRetries > 0 {
+		policy.MaxAttempts = conf.MaxRetries
+	}
+	if conf.RetryDelay > 0 {
+		policy.BaseDelay = conf.RetryDelay
+	}
+	timing := NewTiming()
+	err = policy.Do(context.Background(), conf.Errors, func() error {
+		dialStart := time.Now()
+		c, err := dial(context.Background(), conf.Network, address)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create proxy dialer: %w", err)
+			return err
 		}
-	}
-
-	// 尝试连接
-	for i := 0; i < conf.MaxRetries; i++ {
-		conn, err = dialer.Dial(conf.Network, address)
-		if err == nil {
-			if conf.Network == "tcp" && conf.IsLts {
-				// 使用TLS
-				tlsConn := tls.Client(conn, &tls.Config{
-					InsecureSkipVerify: true,
-					ServerName:         conf.ServerName, // 动态配置 ServerName
-				})
-				err = tlsConn.Handshake()
-				if err == nil {
-					conn = tlsConn
-					break
-				} else {
-					_ = conn.Close()
-				}
-			} else {
-				break
+		timing.MarkConnect(time.Since(dialStart))
+		if conf.Network == "tcp" && conf.IsLts {
+			// 使用TLS，握手失败时连接已关闭
+			tlsStart := time.Now()
+			c, err = ltsHandshake(c, conf.ServerName, fingerprint, conf.DialTimeout)
+			timing.MarkTLS(time.Since(tlsStart))
+			if err != nil {
+				return err
 			}
 		}
-		time.Sleep(conf.RetryDelay)
-	}
+		conn = c
+		return nil
+	})
 
 	if err != nil {
+		release()
+		ReportProxyFailure(selected)
 		return nil, err
 	}
 
-	return &Client{address: address, conn: conn, conf: conf}, nil
+	return &Client{address: address, conn: conn, conf: conf, timing: timing, release: release}, nil
 }
 
-// Send 发送数据
+// newDialer 创建连接目标地址的拨号器，配置了代理时按代理池策略选择代理，返回选中的代理地址
+func newDialer(proxySpec, address string, timeout time.Duration) (proxy.Dialer, string, error) {
+	selected, err := SelectProxy(proxySpec, address)
+	if err != nil {
+		return nil, "", err
+	}
+	if selected == "" {
+		return &net.Dialer{Timeout: timeout}, "", nil
+	}
+	proxyURL, err := url.Parse(selected)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	dialer, err := proxyclient.NewClient(proxyURL)
+	//dialer, err = proxy.FromURL(proxyURL, proxy.Direct)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create proxy dialer: %w", err)
+	}
+	return dialer, selected, nil
+}
+
+// ltsHandshake 在TCP连接上完成TLS握手，fingerprint 非 go 时使用 uTLS 模拟浏览器的 ClientHello，握手失败时关闭连接
+func ltsHandshake(conn net.Conn, serverName, fingerprint string, timeout time.Duration) (net.Conn, error) {
+	if fingerprint != TLSFingerprintGo {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return utlsHandshake(ctx, conn, serverName, fingerprint, true)
+	}
+	tlsConn := tls.Client(conn, &tls.Config{
+		InsecureSkipVerify: true,
+		ServerName:         serverName, // 动态配置 ServerName
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// Send 发送数据，失败时记录错误分类并返回错误；不会重新建立连接，新连接上重发的数据与原会话无关
 func (c *Client) Send(data []byte) error {
 	if c.conn == nil {
 		return errors.New("connection is not established")
@@ -161,16 +209,15 @@ func (c *Client) Send(data []byte) error {
 	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout()))
 	_, err := c.conn.Write(data)
 	if err != nil {
-		var ne net.Error
-		if errors.As(err, &ne) && ne.Timeout() {
-			return c.retryWrite(data)
-		}
+		class := ClassifyError(err)
+		c.conf.Errors.Record(class)
+		c.conf.Errors.Fail(class)
 		return err
 	}
 	return nil
 }
 
-// Receive 接收数据
+// Receive 接收数据，返回已读取的数据与错误；读取超时或连接关闭通常表示服务对探测数据无响应，不记录为失败
 func (c *Client) Receive() ([]byte, error) {
 	if c.conn == nil {
 		return nil, errors.New("connection is not established")
@@ -179,52 +226,84 @@ func (c *Client) Receive() ([]byte, error) {
 	_ = c.conn.SetReadDeadline(time.Now().Add(c.readTimeout()))
 	buf := make([]byte, c.readSize())
 	n, err := c.conn.Read(buf)
-	if err != nil {
-		return nil, c.retryRead(buf)
+	if n > 0 {
+		c.timing.MarkFirstByte()
 	}
-	return buf[:n], nil
+	return buf[:n], err
 }
 
-// Close 关闭连接
-func (c *Client) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
+// ReadN 在当前连接上读取最多 size 字节（size 不大于0时使用 ReadSize），优先返回 ReadUntil 多读取的数据；
+// 用于多步会话，读取失败时不重新建立连接
+func (c *Client) ReadN(size int) ([]byte, error) {
+	if c.conn == nil {
+		return nil, errors.New("connection is not established")
 	}
-	return nil
+	if size <= 0 {
+		size = c.readSize()
+	}
+	if len(c.pending) > 0 {
+		n := min(size, len(c.pending))
+		data := c.pending[:n:n]
+		c.pending = c.pending[n:]
+		return data, nil
+	}
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.readTimeout()))
+	buf := make([]byte, size)
+	n, err := c.conn.Read(buf)
+	if n > 0 {
+		c.timing.MarkFirstByte()
+	}
+	return buf[:n], err
 }
 
-// retryWrite 重试写入数据
-func (c *Client) retryWrite(data []byte) error {
-	for i := 0; i < c.maxRetries(); i++ {
-		time.Sleep(c.retryTimeout())
-		conn, err := net.DialTimeout(c.network(), c.conn.RemoteAddr().String(), c.dialTimeout())
-		if err == nil {
-			c.conn = conn
-			_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout()))
-			_, err = c.conn.Write(data)
-			if err == nil {
-				return nil
+// ReadUntil 持续读取直到 match 返回匹配的结束位置（不小于0），返回截止到匹配结束的数据，多读取的部分留给后续读取；
+// 超过 limit 字节、超时或连接关闭时返回已读取的数据与错误
+func (c *Client) ReadUntil(match func(data []byte) int, limit int) ([]byte, error) {
+	if c.conn == nil {
+		return nil, errors.New("connection is not established")
+	}
+	data := c.pending
+	c.pending = nil
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.readTimeout()))
+	buf := make([]byte, c.readSize())
+	for {
+		if end := match(data); end >= 0 {
+			c.pending = data[end:]
+			return data[:end:end], nil
+		}
+		if len(data) >= limit {
+			return data, fmt.Errorf("read %d bytes without match", len(data))
+		}
+		n, err := c.conn.Read(buf)
+		if n > 0 {
+			c.timing.MarkFirstByte()
+			data = append(data, buf[:n]...)
+		}
+		if err != nil {
+			if end := match(data); end >= 0 {
+				c.pending = data[end:]
+				return data[:end:end], nil
 			}
+			return data, err
 		}
 	}
-	return fmt.Errorf("failed to send data after %d retries: %w", c.maxRetries(), errors.New("connection is closed"))
 }
 
-// retryRead 重试读取数据
-func (c *Client) retryRead(buf []byte) error {
-	for i := 0; i < c.maxRetries(); i++ {
-		time.Sleep(c.retryTimeout())
-		conn, err := net.DialTimeout(c.network(), c.conn.RemoteAddr().String(), c.dialTimeout())
-		if err == nil {
-			c.conn = conn
-			_ = c.conn.SetReadDeadline(time.Now().Add(c.readTimeout()))
-			n, err := c.conn.Read(buf)
-			if err == nil && n > 0 {
-				return nil
-			}
-		}
+// Timing 返回连接的耗时记录
+func (c *Client) Timing() *Timing {
+	return c.timing
+}
+
+// Close 关闭连接并释放速率限制器的并发槽位
+func (c *Client) Close() error {
+	if c.release != nil {
+		c.release()
 	}
-	return fmt.Errorf("failed to receive data after %d retries: %w", c.maxRetries(), errors.New("connection is closed"))
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
 }
 
 func (c *Client) network() string {
@@ -234,13 +313,6 @@ func (c *Client) network() string {
 	return DefaultNetwork
 }
 
-func (c *Client) dialTimeout() time.Duration {
-	if c.conf.DialTimeout != 0 {
-		return c.conf.DialTimeout
-	}
-	return DefaultDialTimeout
-}
-
 func (c *Client) writeTimeout() time.Duration {
 	if c.conf.WriteTimeout != 0 {
 		return c.conf.WriteTimeout
@@ -255,20 +327,6 @@ func (c *Client) readTimeout() time.Duration {
 	return DefaultReadTimeout
 }
 
-func (c *Client) retryTimeout() time.Duration {
-	if c.conf.RetryDelay != 0 {
-		return c.conf.RetryDelay
-	}
-	return DefaultRetryDelay
-}
-
-func (c *Client) maxRetries() int {
-	if c.conf.MaxRetries != 0 {
-		return c.conf.MaxRetries
-	}
-	return DefaultMaxRetries
-}
-
 func (c *Client) readSize() int {
 	if c.conf.ReadSize != 0 {
 		return c.conf.ReadSize
@@ -0,0 +1,182 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: portscan.go
+    @Date: 2025/6/23 上午9:40*
+*/
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// 端口协议识别结果
+const (
+	ServiceHTTP  = "http"  // HTTP服务
+	ServiceHTTPS = "https" // TLS之上的HTTP服务
+	ServiceTLS   = "tls"   // TLS之上的非HTTP服务
+	ServiceTCP   = "tcp"   // 其他TCP服务
+)
+
+// TopPorts100 最常见的100个TCP端口，与 nmap --top-ports 100 一致
+var TopPorts100 = []int{
+	7, 9, 13, 21, 22, 23, 25, 26, 37, 53, 79, 80, 81, 88, 106, 110, 111, 113, 119, 135,
+	139, 143, 144, 179, 199, 389, 427, 443, 444, 445, 465, 513, 514, 515, 543, 544, 548, 554, 587, 631,
+	646, 873, 990, 993, 995, 1025, 1026, 1027, 1028, 1029, 1110, 1433, 1720, 1723, 1755, 1900, 2000, 2001, 2049, 2121,
+	2717, 3000, 3128, 3306, 3389, 3986, 4899, 5000, 5009, 5051, 5060, 5101, 5190, 5357, 5432, 5631, 5666, 5800, 5900, 6000,
+	6001, 6646, 7070, 8000, 8008, 8009, 8080, 8081, 8443, 8888, 9100, 9999, 10000, 32768, 49152, 49153, 49154, 49155, 49156, 49157,
+}
+
+// httpProbe 协议识别时发送的HTTP请求
+const httpProbe = "GET / HTTP/1.0\r\nHost: %s\r\nUser-Agent: Mozilla/5.0\r\nAccept: */*\r\n\r\n"
+
+// ParsePorts 解析端口列表，支持逗号分隔的单个端口、端口范围（如 8000-9000）与 top100，返回去重排序后的端口
+func ParsePorts(spec string) ([]int, error) {
+	unique := make(map[int]struct{})
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if item == "top100" {
+			for _, port := range TopPorts100 {
+				unique[port] = struct{}{}
+			}
+			continue
+		}
+		start, end, isRange := strings.Cut(item, "-")
+		low, err := parsePort(start)
+		if err != nil {
+			return nil, err
+		}
+		high := low
+		if isRange {
+			if high, err = parsePort(end); err != nil {
+				return nil, err
+			}
+			if high < low {
+				return nil, fmt.Errorf("端口范围 %q 无效", item)
+			}
+		}
+		for port := low; port <= high; port++ {
+			unique[port] = struct{}{}
+		}
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("端口列表 %q 为空", spec)
+	}
+	ports := make([]int, 0, len(unique))
+	for port := range unique {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// parsePort 解析单个端口，端口需在1-65535之间
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("端口 %q 无效", value)
+	}
+	return port, nil
+}
+
+// dialPort 等待速率限制后建立到目标地址的TCP连接，连接经过代理与自定义解析，返回的 release 用于释放并发槽位
+func dialPort(address, proxySpec string, timeout time.Duration) (net.Conn, func(), error) {
+	dialer, selected, err := newDialer(proxySpec, address, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	release, err := Limiter.Acquire(context.Background(), HostKey(address))
+	if err != nil {
+		return nil, nil, err
+	}
+	dial := resolveDial(func(_ context.Context, network, addr string) (net.Conn, error) {
+		return dialer.Dial(network, addr)
+	})
+	conn, err := dial(context.Background(), DefaultNetwork, address)
+	if err != nil {
+		release()
+		ReportProxyFailure(selected)
+		return nil, nil, err
+	}
+	return conn, release, nil
+}
+
+// ScanPort 对 host:port 执行TCP连接扫描，连接建立成功时返回 true
+func ScanPort(address, proxySpec string, timeout time.Duration) bool {
+	conn, release, err := dialPort(address, proxySpec, timeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	release()
+	return true
+}
+
+// DetectService 识别开放端口的协议：先尝试TLS握手，成功后发送HTTP请求区分 https 与 tls；
+// 握手失败时重新连接发送HTTP请求，响应以 HTTP/ 开头为 http，否则为 tcp。serverName 为空时不发送SNI
+func DetectService(address, serverName, proxySpec string, timeout time.Duration) string {
+	if timeout <= 0 {
+		timeout = DefaultReadTimeout
+	}
+	host := serverName
+	if host == "" {
+		host = address
+	}
+
+	if conn, release, err := dialPort(address, proxySpec, timeout); err == nil {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+		tlsConn, err := ltsHandshake(conn, serverName, TLSFingerprintGo, timeout)
+		if err == nil {
+			isHTTP := probeHTTP(tlsConn, host)
+			_ = tlsConn.Close()
+			release()
+			if isHTTP {
+				return ServiceHTTPS
+			}
+			return ServiceTLS
+		}
+		_ = conn.Close()
+		release()
+	}
+
+	conn, release, err := dialPort(address, proxySpec, timeout)
+	if err != nil {
+		return ServiceTCP
+	}
+	defer release()
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if probeHTTP(conn, host) {
+		return ServiceHTTP
+	}
+	return ServiceTCP
+}
+
+// probeHTTP 在连接上发送HTTP请求，响应以 HTTP/ 开头时返回 true
+func probeHTTP(conn net.Conn, host string) bool {
+	if _, err := fmt.Fprintf(conn, httpProbe, host); err != nil {
+		return false
+	}
+	buf := make([]byte, 5)
+	n := 0
+	for n < len(buf) {
+		read, err := conn.Read(buf[n:])
+		n += read
+		if err != nil {
+			break
+		}
+	}
+	return bytes.Equal(buf[:n], []byte("HTTP/"))
+}
@@ -0,0 +1,309 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: proxy.go
+    @Date: 2025/6/19 上午10:30*
+*/
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"gxx/utils/logger"
+	"hash/fnv"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 代理选择策略
+const (
+	ProxyRoundRobin = "round-robin" // 轮询
+	ProxyRandom     = "random"      // 随机
+	ProxySticky     = "sticky"      // 同一目标固定使用同一个代理
+)
+
+const (
+	proxyCheckInterval = 30 * time.Second // 健康检查间隔，被剔除的代理在检查通过后恢复使用
+	proxyCheckTimeout  = 5 * time.Second  // 健康检查连接超时时间
+	proxyRecheckDelay  = 5 * time.Second  // 请求失败触发健康检查的最短间隔
+)
+
+var (
+	ProxyStrategy = ProxyRoundRobin // 代理选择策略，可通过命令行修改
+	proxyPools    sync.Map          // 按代理配置缓存代理池
+)
+
+// proxyEntry 代理池中的单个代理
+type proxyEntry struct {
+	url       string // 代理地址，格式：scheme://host:port
+	addr      string // 代理服务器地址 host:port，用于健康检查
+	healthy   bool
+	checking  bool
+	lastCheck time.Time
+}
+
+// ProxyPool 代理池，按策略选择代理并定期检查代理是否可用，不可用的代理暂时剔除
+type ProxyPool struct {
+	mu       sync.Mutex
+	entries  []*proxyEntry
+	strategy string
+	next     int
+}
+
+// ParseProxyList 解析代理配置，支持逗号分隔的代理列表或每行一个代理的文件，未指定协议时默认为 http
+func ParseProxyList(spec string) ([]string, error) {
+	var items []string
+	if info, err := os.Stat(spec); err == nil && !info.IsDir() {
+		file, err := os.Open(spec)
+		if err != nil {
+			return nil, fmt.Errorf("读取代理文件失败: %v", err)
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				items = append(items, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("读取代理文件失败: %v", err)
+		}
+	} else {
+		for _, item := range strings.Split(spec, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	proxies := make([]string, 0, len(items))
+	for _, item := range items {
+		proxyURL, _, err := normalizeProxy(item)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, proxyURL)
+	}
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("代理列表为空: %s", spec)
+	}
+	return proxies, nil
+}
+
+// normalizeProxy 补全代理协议与端口，返回代理地址与代理服务器的 host:port
+func normalizeProxy(item string) (string, string, error) {
+	if !strings.Contains(item, "://") {
+		item = HttpPrefix + item
+	}
+	u, err := url.Parse(item)
+	if err != nil || u.Hostname() == "" {
+		return "", "", fmt.Errorf("代理地址解析失败: %s", item)
+	}
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h", "socks4", "socks4a":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return u.String(), net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// NewProxyPool 根据代理配置创建代理池，strategy 为空时使用轮询
+func NewProxyPool(spec, strategy string) (*ProxyPool, error) {
+	switch strategy {
+	case "":
+		strategy = ProxyRoundRobin
+	case ProxyRoundRobin, ProxyRandom, ProxySticky:
+	default:
+		return nil, fmt.Errorf("未知的代理选择策略: %s，可选：%s、%s、%s", strategy, ProxyRoundRobin, ProxyRandom, ProxySticky)
+	}
+	proxies, err := ParseProxyList(spec)
+	if err != nil {
+		return nil, err
+	}
+	pool := &ProxyPool{strategy: strategy}
+	for _, proxyURL := range proxies {
+		_, addr, _ := normalizeProxy(proxyURL)
+		pool.entries = append(pool.entries, &proxyEntry{url: proxyURL, addr: addr, healthy: true})
+	}
+	// 只有一个代理时无需健康检查，剔除后也没有可替换的代理
+	if len(pool.entries) > 1 {
+		go pool.healthLoop()
+	}
+	return pool, nil
+}
+
+// InitProxyPool 创建代理池并立即检查一次代理是否可用，供命令行启动时调用，spec 为空时不使用代理
+func InitProxyPool(spec, strategy string) error {
+	if spec == "" {
+		return nil
+	}
+	pool, err := NewProxyPool(spec, strategy)
+	if err != nil {
+		return err
+	}
+	if len(pool.entries) > 1 {
+		pool.checkAll()
+	}
+	proxyPools.Store(spec, pool)
+	logger.Debug(fmt.Sprintf("代理池：共 %d 个代理，可用 %d 个，选择策略：%s", len(pool.entries), pool.Healthy(), pool.strategy))
+	return nil
+}
+
+// getProxyPool 返回代理配置对应的代理池，不存在时使用当前策略创建
+func getProxyPool(spec string) (*ProxyPool, error) {
+	if pool, ok := proxyPools.Load(spec); ok {
+		return pool.(*ProxyPool), nil
+	}
+	pool, err := NewProxyPool(spec, ProxyStrategy)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := proxyPools.LoadOrStore(spec, pool)
+	return actual.(*ProxyPool), nil
+}
+
+// SelectProxy 根据代理配置为目标选择一个代理，spec 为空时返回空（直连）
+func SelectProxy(spec, target string) (string, error) {
+	if spec == "" {
+		return "", nil
+	}
+	pool, err := getProxyPool(spec)
+	if err != nil {
+		return "", err
+	}
+	return pool.Pick(target), nil
+}
+
+// ReportProxyFailure 通过代理请求失败时调用，触发对该代理的健康检查
+func ReportProxyFailure(proxyURL string) {
+	if proxyURL == "" {
+		return
+	}
+	proxyPools.Range(func(_, value any) bool {
+		value.(*ProxyPool).reportFailure(proxyURL)
+		return true
+	})
+}
+
+// Pick 按策略选择代理，全部代理不可用时仍从全部代理中选择，避免直连暴露真实地址
+func (p *ProxyPool) Pick(target string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	candidates := make([]*proxyEntry, 0, len(p.entries))
+	for _, e := range p.entries {
+		if e.healthy {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = p.entries
+	}
+
+	switch p.strategy {
+	case ProxyRandom:
+		return candidates[rand.IntN(len(candidates))].url
+	case ProxySticky:
+		// 按主机哈希到固定代理，该代理不可用时顺延到下一个可用代理
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(HostKey(target)))
+		start := int(h.Sum32() % uint32(len(p.entries)))
+		for i := range p.entries {
+			if e := p.entries[(start+i)%len(p.entries)]; e.healthy {
+				return e.url
+			}
+		}
+		return p.entries[start].url
+	default:
+		p.next = (p.next + 1) % len(candidates)
+		return candidates[p.next].url
+	}
+}
+
+// Healthy 返回可用代理数量
+func (p *ProxyPool) Healthy() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	count := 0
+	for _, e := range p.entries {
+		if e.healthy {
+			count++
+		}
+	}
+	return count
+}
+
+// reportFailure 代理请求失败时检查该代理，同一代理的检查间隔不小于 proxyRecheckDelay
+func (p *ProxyPool) reportFailure(proxyURL string) {
+	if len(p.entries) < 2 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.entries {
+		if e.url == proxyURL && !e.checking && time.Since(e.lastCheck) >= proxyRecheckDelay {
+			e.checking = true
+			go p.check(e)
+		}
+	}
+}
+
+// check 连接代理服务器检查代理是否可用
+func (p *ProxyPool) check(e *proxyEntry) {
+	conn, err := net.DialTimeout("tcp", e.addr, proxyCheckTimeout)
+	if err == nil {
+		_ = conn.Close()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	healthy := err == nil
+	if healthy != e.healthy {
+		if healthy {
+			logger.Debug(fmt.Sprintf("代理 %s 恢复可用", e.url))
+		} else {
+			logger.Debug(fmt.Sprintf("代理 %s 不可用，暂时剔除：%v", e.url, err))
+		}
+	}
+	e.healthy, e.checking, e.lastCheck = healthy, false, time.Now()
+}
+
+// checkAll 并发检查全部代理
+func (p *ProxyPool) checkAll() {
+	var wg sync.WaitGroup
+	p.mu.Lock()
+	for _, e := range p.entries {
+		if e.checking {
+			continue
+		}
+		e.checking = true
+		wg.Add(1)
+		go func(e *proxyEntry) {
+			defer wg.Done()
+			p.check(e)
+		}(e)
+	}
+	p.mu.Unlock()
+	wg.Wait()
+}
+
+// healthLoop 定期检查全部代理
+func (p *ProxyPool) healthLoop() {
+	ticker := time.NewTicker(proxyCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		p.checkAll()
+	}
+}
@@ -0,0 +1,332 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: ratelimit.go
+    @Date: 2025/6/18 上午9:20*
+*/
+package network
+
+import (
+	"fmt"
+	"gxx/utils/logger"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	readerutil "github.com/zan8in/retryablehttp/pkg/utils/readerutil"
+	"golang.org/x/net/context"
+)
+
+const (
+	backoffBase       = time.Second      // 429/503 无 Retry-After 时的初始退避时间
+	backoffMax        = 60 * time.Second // 最大退避时间，Retry-After 超过该值时按该值退避且不再重试
+	maxBackoffRetries = 2                // 429/503 响应的最大重试次数
+	hostIdleTTL       = 10 * time.Minute // 主机限速状态的保留时间
+)
+
+// RateLimitOptions 速率限制配置，值为0时不限制
+type RateLimitOptions struct {
+	HostConcurrency int     // 单个主机的最大并发请求数
+	HostRate        float64 // 单个主机每秒请求数
+	GlobalRate      float64 // 全局每秒请求数
+}
+
+// hostState 单个主机的限速状态
+type hostState struct {
+	sem          chan struct{} // 并发信号量，不限制并发时为空
+	next         time.Time     // 下一次允许发送请求的时间
+	blockedUntil time.Time     // 429/503 退避结束时间
+	failures     int           // 连续 429/503 次数
+	lastUsed     time.Time
+}
+
+// RateLimiter 按主机限制并发与请求速率，并在收到 429/503 时自动退避
+type RateLimiter struct {
+	mu          sync.Mutex
+	opts        RateLimitOptions
+	hosts       map[string]*hostState
+	globalNext  time.Time
+	lastCleanup time.Time
+}
+
+// Limiter 全局速率限制器，HTTP、raw 与 TCP/UDP 请求共用
+var Limiter = NewRateLimiter(RateLimitOptions{})
+
+// NewRateLimiter 创建速率限制器
+func NewRateLimiter(opts RateLimitOptions) *RateLimiter {
+	return &RateLimiter{opts: opts, hosts: make(map[string]*hostState), lastCleanup: time.Now()}
+}
+
+// SetRateLimit 设置全局速率限制，需在扫描开始前调用
+func SetRateLimit(opts RateLimitOptions) {
+	Limiter.mu.Lock()
+	Limiter.opts = opts
+	Limiter.hosts = make(map[string]*hostState)
+	Limiter.mu.Unlock()
+	logger.Debug(fmt.Sprintf("速率限制：单主机并发=%d，单主机速率=%.2f/s，全局速率=%.2f/s",
+		opts.HostConcurrency, opts.HostRate, opts.GlobalRate))
+}
+
+// HostKey 返回用于限速的主机名，支持URL、host:port 与纯主机名
+func HostKey(target string) string {
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			return strings.ToLower(u.Hostname())
+		}
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(target)
+}
+
+// state 返回主机的限速状态，不存在时创建，调用方需持有锁
+func (l *RateLimiter) state(host string) *hostState {
+	now := time.Now()
+	if now.Sub(l.lastCleanup) > hostIdleTTL {
+		for key, st := range l.hosts {
+			if now.Sub(st.lastUsed) > hostIdleTTL && len(st.sem) == 0 {
+				delete(l.hosts, key)
+			}
+		}
+		l.lastCleanup = now
+	}
+	st, ok := l.hosts[host]
+	if !ok {
+		st = &hostState{}
+		if l.opts.HostConcurrency > 0 {
+			st.sem = make(chan struct{}, l.opts.HostConcurrency)
+		}
+		l.hosts[host] = st
+	}
+	st.lastUsed = now
+	return st
+}
+
+// Acquire 等待主机的并发槽位、退避时间与速率限制，返回的 release 用于释放并发槽位，可重复调用
+func (l *RateLimiter) Acquire(ctx context.Context, host string) (func(), error) {
+	release, err := l.AcquireSlot(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Wait(ctx, host); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// AcquireSlot 只等待主机的并发槽位，返回的 release 用于释放并发槽位，可重复调用；
+// 速率限制由每次实际发送请求前的 Wait 负责
+func (l *RateLimiter) AcquireSlot(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	st := l.state(host)
+	l.mu.Unlock()
+
+	if st.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case st.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-st.sem }) }, nil
+}
+
+// Wait 等待主机的退避时间与速率限制（主机速率与全局速率），不占用并发槽位
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	st := l.state(host)
+	hostInterval, globalInterval := rateInterval(l.opts.HostRate), rateInterval(l.opts.GlobalRate)
+	l.mu.Unlock()
+
+	// 等待退避结束并预约主机速率槽位，等待期间退避时间可能被延长，需要重新检查
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		if st.blockedUntil.After(now) {
+			at := st.blockedUntil
+			l.mu.Unlock()
+			if err := sleepUntil(ctx, at); err != nil {
+				return err
+			}
+			continue
+		}
+		at := now
+		if hostInterval > 0 {
+			if st.next.After(at) {
+				at = st.next
+			}
+			st.next = at.Add(hostInterval)
+		}
+		l.mu.Unlock()
+		if err := sleepUntil(ctx, at); err != nil {
+			return err
+		}
+		break
+	}
+
+	// 预约全局速率槽位
+	if globalInterval > 0 {
+		l.mu.Lock()
+		at := time.Now()
+		if l.globalNext.After(at) {
+			at = l.globalNext
+		}
+		l.globalNext = at.Add(globalInterval)
+		l.mu.Unlock()
+		if err := sleepUntil(ctx, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Observe 根据响应状态码调整主机的退避时间，返回本次退避时长，未退避时返回0
+func (l *RateLimiter) Observe(host string, status int, header http.Header) time.Duration {
+	if status == 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st := l.state(host)
+	if status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+		st.failures = 0
+		return 0
+	}
+	st.failures++
+	wait := parseRetryAfter(header.Get("Retry-After"))
+	if wait <= 0 {
+		wait = backoffBase << (st.failures - 1)
+	}
+	if wait > backoffMax || wait <= 0 {
+		wait = backoffMax
+	}
+	if until := time.Now().Add(wait); until.After(st.blockedUntil) {
+		st.blockedUntil = until
+	}
+	logger.Debug(fmt.Sprintf("主机 %s 返回 %d，退避 %v", host, status, wait))
+	return wait
+}
+
+// parseRetryAfter 解析 Retry-After，支持秒数与HTTP日期两种格式
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// rateInterval 将每秒请求数转换为请求间隔，rate 为0时返回0
+func rateInterval(rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / rate)
+}
+
+// sleepUntil 等待到指定时间，上下文取消时提前返回错误
+func sleepUntil(ctx context.Context, at time.Time) error {
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AcquireHost 在发送请求前等待目标主机的并发槽位与速率限制，返回的 release 用于释放并发槽位，
+// 用于不经过 limitTransport 发送的请求；调用方应在创建带超时的上下文之前调用，避免排队时间计入请求超时
+func AcquireHost(target string) func() {
+	release, err := Limiter.Acquire(context.Background(), HostKey(target))
+	if err != nil {
+		return func() {}
+	}
+	return release
+}
+
+// AcquireHostSlot 在发送请求前只等待目标主机的并发槽位，用于经过 limitTransport 发送的请求（速率限制在每次实际发送时等待）；
+// 持有槽位期间对同一主机发起的子请求（如抓取icon、i18n文件）不能再次获取槽位，应在读取完响应体后尽早释放
+func AcquireHostSlot(target string) func() {
+	release, err := Limiter.AcquireSlot(context.Background(), HostKey(target))
+	if err != nil {
+		return func() {}
+	}
+	return release
+}
+
+// RateLimited 包装传输层，每次实际发送请求（包括重试与重定向）前等待速率限制，收到 429/503 时退避，不占用并发槽位
+func RateLimited(base http.RoundTripper) http.RoundTripper {
+	return &limitTransport{base: base}
+}
+
+// limitTransport 每次实际请求（包括重试与重定向）前等待主机与全局速率限制，并记录响应状态，
+// 收到 429/503 时退避并重试，超过最大退避时间、请求体无法重放或请求超时时直接返回响应
+type limitTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip 发送请求
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := HostKey(req.URL.String())
+	for attempt := 0; ; attempt++ {
+		// 等待速率限制与退避的时间不计入请求耗时
+		waitStart := time.Now()
+		if err := Limiter.Wait(req.Context(), host); err != nil {
+			return nil, err
+		}
+		timingFromContext(req.Context()).Exclude(time.Since(waitStart))
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		wait := Limiter.Observe(host, resp.StatusCode, resp.Header)
+		if wait == 0 || wait >= backoffMax || attempt >= maxBackoffRetries {
+			return resp, nil
+		}
+		if !replayable(req) {
+			return resp, nil
+		}
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return resp, nil
+		}
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return resp, nil
+			}
+		}
+		// 退避时间已记录在主机状态中，下一次 Wait 会等待退避结束
+		_ = resp.Body.Close()
+		req = retry
+	}
+}
+
+// replayable 判断请求体能否重新发送，retryablehttp 的请求体读取完毕后会自动重置
+func replayable(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true
+	}
+	_, ok := req.Body.(*readerutil.ReusableReadCloser)
+	return ok
+}
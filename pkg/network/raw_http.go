@@ -16,17 +16,24 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/projectdiscovery/rawhttp"
+	"golang.org/x/net/context"
 )
 
 var (
-	rawHttpClient *rawhttp.Client
+	rawHttpClient  *rawhttp.Client
+	rawHttpClients sync.Map // 按代理缓存的raw客户端，rawhttp 只能在创建客户端时指定代理
 )
 
 type RawHttp struct {
 	RawhttpClient *rawhttp.Client
+	CookieJar     http.CookieJar // 会话cookie jar，为空时不保持会话
+	Timing        *Timing        // 请求耗时记录，为空时不记录
+	VHost         string         // 虚拟主机，设置后作为 Host 请求头，raw请求无法设置 SNI
+	MaxBody       int64          // 响应体读取上限（字节），为0时使用 MaxDefaultBody
 }
 
 func GetRawHTTP(timeout int) *rawhttp.Client {
@@ -38,6 +45,58 @@ func GetRawHTTP(timeout int) *rawhttp.Client {
 	return rawHttpClient
 }
 
+// GetRawHTTPProxy 返回经过代理的raw客户端，proxy 为代理配置，按代理池策略为目标选择代理，未配置代理时返回直连客户端
+func GetRawHTTPProxy(timeout int, proxy, target string) (*rawhttp.Client, error) {
+	proxyURL, err := SelectProxy(proxy, target)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == "" {
+		return GetRawHTTP(timeout), nil
+	}
+	if client, ok := rawHttpClients.Load(proxyURL); ok {
+		return client.(*rawhttp.Client), nil
+	}
+	rawHttpOptions := rawhttp.DefaultOptions
+	rawHttpOptions.Timeout = time.Duration(timeout) * time.Second
+	rawHttpOptions.Proxy = proxyURL
+	rawHttpOptions.ProxyDialTimeout = rawHttpOptions.Timeout
+	client, _ := rawHttpClients.LoadOrStore(proxyURL, rawhttp.NewClient(rawHttpOptions))
+	return client.(*rawhttp.Client), nil
+}
+
+// doRaw 发送raw请求，配置虚拟主机时关闭自动 Host 请求头并使用虚拟主机作为 Host；
+// raw客户端使用自带的DNS解析，配置了自定义解析时先解析为IP再连接，原主机名作为 Host。
+// 同时返回实际连接的地址，配置了自定义解析时为解析后的IP地址
+func (r *RawHttp) doRaw(method, baseurl, path string, headers map[string][]string, body io.Reader) (*http.Response, string, error) {
+	resolved, originalHost, err := ResolveURL(baseurl)
+	if err != nil {
+		return nil, baseurl, err
+	}
+	vhost := r.VHost
+	if vhost == "" {
+		vhost = originalHost
+	}
+	if vhost == "" {
+		resp, err := r.RawhttpClient.DoRaw(method, baseurl, path, headers, body)
+		return resp, baseurl, err
+	}
+	u, err := url.Parse(resolved)
+	if err != nil {
+		return nil, resolved, err
+	}
+	for key := range headers {
+		if strings.EqualFold(key, "Host") {
+			delete(headers, key)
+		}
+	}
+	headers["Host"] = []string{VhostHeader(u, vhost)}
+	options := *r.RawhttpClient.Options
+	options.AutomaticHostHeader = false
+	resp, err := r.RawhttpClient.DoRawWithOptions(method, resolved, path, headers, body, &options)
+	return resp, resolved, err
+}
+
 func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string]any) error {
 	var err error
 	var resp *http.Response
@@ -52,7 +111,24 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 		return fmt.Errorf("parse Failed, %s", err.Error())
 	}
 
-	resp, err = r.RawhttpClient.DoRaw(rhttp.Method, baseurl, rhttp.Path, ExpandMapValues(rhttp.Headers), io.NopCloser(strings.NewReader(rhttp.Data)))
+	// 携带会话中已保存的cookie
+	sessionURL, _ := url.Parse(strings.TrimRight(baseurl, "/") + rhttp.Path)
+	if r.CookieJar != nil && sessionURL != nil {
+		r.addSessionCookies(rhttp, sessionURL)
+	}
+
+	if r.Timing == nil {
+		r.Timing = NewTiming()
+	}
+	host := HostKey(baseurl)
+	release, err := Limiter.Acquire(context.Background(), host)
+	if err != nil {
+		return fmt.Errorf("rateLimit Failed, %s", err.Error())
+	}
+	// 等待限速许可后再开始计时
+	r.Timing.Restart()
+	resp, dialURL, err := r.doRaw(rhttp.Method, baseurl, rhttp.Path, ApplyGlobalHeaders(ExpandMapValues(rhttp.Headers)), io.NopCloser(strings.NewReader(rhttp.Data)))
+	release()
 	if err != nil {
 		//fmt.Println(err.Error())
 		return fmt.Errorf("doRaw Failed, %s", err.Error())
@@ -61,11 +137,20 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 		_ = Body.Close()
 	}(resp.Body)
 
+	r.Timing.MarkFirstByte()
+	Limiter.Observe(host, resp.StatusCode, resp.Header)
+
+	// 保存响应中的Set-Cookie到会话
+	if r.CookieJar != nil && sessionURL != nil {
+		r.CookieJar.SetCookies(sessionURL, resp.Cookies())
+	}
+
 	// 限制读取大小，避免异常大响应体
-	respBody, err := io.ReadAll(io.LimitReader(resp.Body, MaxDefaultBody))
+	respBody, truncated, err := ReadBody(resp, r.MaxBody)
 	if err != nil {
 		return fmt.Errorf("readAll Failed, %s", err.Error())
 	}
+	r.Timing.Done()
 
 	dumpedResponseHeaders, err := httputil.DumpResponse(resp, false)
 	if err != nil {
@@ -77,27 +162,17 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 	if requrl, err := url.Parse(baseurl); err == nil {
 		tempResultResponse.Url = common.Url2UrlType(requrl)
 	}
-	newheader2 := make(map[string]string)
-	respHeaderSlice := strings.Split(strings.TrimSpace(string(dumpedResponseHeaders)), "\n")
-	for _, h := range respHeaderSlice {
-		h = strings.Trim(h, "\r\n")
-		hslice := strings.SplitN(h, ":", 2)
-		if len(hslice) != 2 {
-			continue
-		}
-		k := strings.ToLower(hslice[0])
-		v := strings.TrimLeft(hslice[1], " ")
-		if newheader2[k] != "" {
-			newheader2[k] += v
-		} else {
-			newheader2[k] = v
-		}
-	}
-	tempResultResponse.Headers = newheader2
+	tempResultResponse.Headers, tempResultResponse.HeaderValues = Header2Proto(resp.Header)
+	tempResultResponse.Cookies, tempResultResponse.CookieAttributes = Cookies2Proto(resp.Header)
 	tempResultResponse.ContentType = resp.Header.Get("Content-Type")
 	tempResultResponse.Body = respBody
+	tempResultResponse.Text, _ = DecodeText(respBody, resp.Header.Get("Content-Type"))
+	tempResultResponse.Truncated = truncated
 	tempResultResponse.Raw = []byte(string(dumpedResponseHeaders) + "\n" + string(respBody))
 	tempResultResponse.RawHeader = dumpedResponseHeaders
+	tempResultResponse.Latency = r.Timing.Latency()
+	tempResultResponse.Timing = r.Timing.ToProto()
+	tempResultResponse.Conn = ConnInfoFromURL(dialURL)
 	variableMap["response"] = tempResultResponse
 
 	tempResultRequest := &proto.Request{}
@@ -145,6 +220,26 @@ func (r *RawHttp) RawHttpRequest(request, baseurl string, variableMap map[string
 	return err
 }
 
+// addSessionCookies 将会话cookie合并到raw请求的Cookie头中
+func (r *RawHttp) addSessionCookies(rhttp *Request, u *url.URL) {
+	cookies := r.CookieJar.Cookies(u)
+	if len(cookies) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	sessionCookie := strings.Join(parts, "; ")
+	for k, v := range rhttp.Headers {
+		if strings.EqualFold(k, "Cookie") {
+			rhttp.Headers[k] = v + "; " + sessionCookie
+			return
+		}
+	}
+	rhttp.Headers["Cookie"] = sessionCookie
+}
+
 func AssignVariableRaw(find string, variableMap map[string]any) string {
 	for k, v := range variableMap {
 		newstr := fmt.Sprintf("%v", v)
@@ -15,8 +15,12 @@ func RawParse(nc *Client, data []byte, res []byte, variableMap map[string]any) e
 	variableMap["request"] = &proto.Request{
 		Raw: []byte(nc.address + "\r\n" + string(data)),
 	}
+	nc.timing.Done()
 	variableMap["response"] = &proto.Response{
-		Raw: res,
+		Raw:     res,
+		Latency: nc.timing.Latency(),
+		Timing:  nc.timing.ToProto(),
+		Conn:    ConnInfoFromConn(nc.conn, nc.network()),
 	}
 	variableMap["fulltarget"] = nc.address
 	return nil
@@ -0,0 +1,287 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: resolver.go
+    @Date: 2025/6/21 下午2:10*
+*/
+package network
+
+import (
+	"fmt"
+	"gxx/utils/logger"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultResolverTimeout = 3 * time.Second  // 单个DNS服务器的默认查询超时
+	dnsCacheTTL            = 5 * time.Minute  // 解析成功的缓存时间
+	dnsNegativeCacheTTL    = 30 * time.Second // 解析失败的缓存时间，避免对不存在的域名重复查询
+)
+
+var (
+	hostMappings    = map[string][]string{} // 静态主机映射，主机名（小写）→ IP列表，对应 -resolve
+	dnsServers      []dnsServer             // 自定义DNS服务器，为空时使用系统解析，对应 -resolver
+	ResolverTimeout = DefaultResolverTimeout
+	dnsCache        sync.Map // 自定义DNS服务器的解析缓存，主机名 → *dnsCacheEntry
+	dnsGroup        singleflight.Group
+	resolverMu      sync.RWMutex
+)
+
+// dnsServer 自定义DNS服务器
+type dnsServer struct {
+	addr     string // 服务器地址 ip:port
+	resolver *net.Resolver
+}
+
+// dnsCacheEntry 解析缓存条目
+type dnsCacheEntry struct {
+	ips     []string
+	err     error
+	expires time.Time
+}
+
+// SetHostMappings 设置静态主机映射，格式为 host:ip（与 curl --resolve 类似，不区分端口），同一主机可指定多个IP
+func SetHostMappings(mappings []string) error {
+	parsed := make(map[string][]string, len(mappings))
+	for _, mapping := range mappings {
+		mapping = strings.TrimSpace(mapping)
+		if mapping == "" {
+			continue
+		}
+		host, ip, found := strings.Cut(mapping, ":")
+		host = strings.ToLower(strings.TrimSpace(host))
+		ip = strings.Trim(strings.TrimSpace(ip), "[]")
+		if !found || host == "" || net.ParseIP(ip) == nil {
+			return fmt.Errorf("主机映射格式错误: %s，格式为 host:ip", mapping)
+		}
+		parsed[host] = append(parsed[host], ip)
+	}
+	resolverMu.Lock()
+	hostMappings = parsed
+	resolverMu.Unlock()
+	return nil
+}
+
+// SetResolvers 设置自定义DNS服务器，格式为 ip、ip:port、udp://ip:port 或 tcp://ip:port，
+// 多个服务器按顺序查询直到成功，timeout 为单个服务器的查询超时，为0时使用默认值；servers 为空时使用系统解析
+func SetResolvers(servers []string, timeout time.Duration) error {
+	parsed := make([]dnsServer, 0, len(servers))
+	for _, server := range servers {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		network, addr := "udp", server
+		if u, err := url.Parse(server); err == nil && (u.Scheme == "udp" || u.Scheme == "tcp") {
+			network, addr = u.Scheme, u.Host
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(strings.Trim(addr, "[]"), "53")
+		}
+		host, _, _ := net.SplitHostPort(addr)
+		if net.ParseIP(host) == nil {
+			return fmt.Errorf("DNS服务器地址错误: %s，格式为 ip、ip:port、udp://ip:port 或 tcp://ip:port", server)
+		}
+		parsed = append(parsed, dnsServer{addr: addr, resolver: newServerResolver(network, addr)})
+	}
+	if timeout <= 0 {
+		timeout = DefaultResolverTimeout
+	}
+	resolverMu.Lock()
+	dnsServers = parsed
+	ResolverTimeout = timeout
+	resolverMu.Unlock()
+	ClearDNSCache()
+	return nil
+}
+
+// newServerResolver 创建只向指定DNS服务器查询的解析器
+func newServerResolver(network, addr string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// ClearDNSCache 清空自定义DNS服务器的解析缓存
+func ClearDNSCache() {
+	dnsCache.Range(func(key, _ interface{}) bool {
+		dnsCache.Delete(key)
+		return true
+	})
+}
+
+// CustomResolution 是否配置了静态主机映射或自定义DNS服务器
+func CustomResolution() bool {
+	resolverMu.RLock()
+	defer resolverMu.RUnlock()
+	return len(hostMappings) > 0 || len(dnsServers) > 0
+}
+
+// LookupHost 解析主机名，IP原样返回；优先使用静态主机映射，其次使用自定义DNS服务器（带缓存），均未配置时使用系统解析
+func LookupHost(ctx context.Context, host string) ([]string, error) {
+	if ips, handled, err := customLookup(ctx, host); handled {
+		return ips, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP.String())
+	}
+	return sortIPs(ips), nil
+}
+
+// customLookup 使用静态主机映射或自定义DNS服务器解析主机名，handled 为false时表示未配置自定义解析，由系统解析处理
+func customLookup(ctx context.Context, host string) (ips []string, handled bool, err error) {
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if net.ParseIP(host) != nil {
+		return []string{host}, true, nil
+	}
+
+	resolverMu.RLock()
+	mapped := hostMappings[host]
+	servers, timeout := dnsServers, ResolverTimeout
+	resolverMu.RUnlock()
+
+	if len(mapped) > 0 {
+		return mapped, true, nil
+	}
+	if len(servers) == 0 {
+		return nil, false, nil
+	}
+
+	if cached, ok := dnsCache.Load(host); ok {
+		entry := cached.(*dnsCacheEntry)
+		if time.Now().Before(entry.expires) {
+			return entry.ips, true, entry.err
+		}
+		dnsCache.Delete(host)
+	}
+
+	// 同一主机的并发查询合并为一次
+	result, err, _ := dnsGroup.Do(host, func() (interface{}, error) {
+		ips, err := lookupServers(ctx, servers, timeout, host)
+		ttl := dnsCacheTTL
+		if err != nil {
+			ttl = dnsNegativeCacheTTL
+		}
+		// 调用方取消时不缓存结果
+		if ctx.Err() == nil {
+			dnsCache.Store(host, &dnsCacheEntry{ips: ips, err: err, expires: time.Now().Add(ttl)})
+		}
+		return ips, err
+	})
+	if err != nil {
+		return nil, true, err
+	}
+	return result.([]string), true, nil
+}
+
+// lookupServers 按顺序向DNS服务器查询，返回第一个成功的结果
+func lookupServers(ctx context.Context, servers []dnsServer, timeout time.Duration, host string) ([]string, error) {
+	var lastErr error
+	for _, server := range servers {
+		lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+		addrs, err := server.resolver.LookupIPAddr(lookupCtx, host)
+		cancel()
+		if err == nil && len(addrs) > 0 {
+			ips := make([]string, 0, len(addrs))
+			for _, addr := range addrs {
+				ips = append(ips, addr.IP.String())
+			}
+			logger.Debug(fmt.Sprintf("DNS服务器 %s 解析 %s：%s", server.addr, host, strings.Join(ips, ",")))
+			return sortIPs(ips), nil
+		}
+		if err == nil {
+			err = &net.DNSError{Err: "no addresses returned", Name: host, Server: server.addr}
+		}
+		lastErr = err
+		logger.Debug(fmt.Sprintf("DNS服务器 %s 解析 %s 失败：%v", server.addr, host, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	// 保留 *net.DNSError 以便重试策略识别为DNS解析失败
+	return nil, fmt.Errorf("解析 %s 失败: %w", host, lastErr)
+}
+
+// sortIPs IPv4地址排在IPv6地址之前
+func sortIPs(ips []string) []string {
+	sort.SliceStable(ips, func(i, j int) bool {
+		return strings.Contains(ips[j], ":") && !strings.Contains(ips[i], ":")
+	})
+	return ips
+}
+
+// resolveDial 返回按自定义解析获取目标IP后再建立连接的拨号函数，解析到多个IP时依次尝试；
+// 未配置自定义解析或地址为IP时原样使用 dial
+func resolveDial(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+		ips, handled, err := customLookup(ctx, host)
+		if !handled || (len(ips) == 1 && ips[0] == host) {
+			return dial(ctx, network, addr)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, lastErr
+	}
+}
+
+// ResolveURL 配置了自定义解析时将URL中的主机名替换为解析到的IP，返回新的URL与原始主机（host:port），
+// 供无法替换拨号函数的请求（如raw请求）使用；未配置自定义解析或主机为IP时原样返回，原始主机为空
+func ResolveURL(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || !CustomResolution() {
+		return rawURL, "", nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	ips, handled, err := customLookup(ctx, u.Hostname())
+	if !handled || (len(ips) == 1 && ips[0] == u.Hostname()) {
+		return rawURL, "", nil
+	}
+	if err != nil {
+		return rawURL, "", err
+	}
+	originalHost, port := u.Host, u.Port()
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(ips[0], port)
+	case strings.Contains(ips[0], ":"):
+		u.Host = "[" + ips[0] + "]"
+	default:
+		u.Host = ips[0]
+	}
+	return u.String(), originalHost, nil
+}
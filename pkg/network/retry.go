@@ -0,0 +1,285 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: retry.go
+    @Date: 2025/6/24 下午3:05*
+*/
+package network
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	DefaultRetryAttempts  = 3                      // 默认最大尝试次数（含首次请求）
+	DefaultRetryBaseDelay = 500 * time.Millisecond // 默认首次重试的退避时间
+	DefaultRetryMaxDelay  = 10 * time.Second       // 默认最大退避时间
+)
+
+// 请求错误分类
+const (
+	ErrorDNS     = "dns"     // 域名解析失败
+	ErrorRefused = "refused" // 连接被拒绝
+	ErrorReset   = "reset"   // 连接被重置或提前关闭
+	ErrorTimeout = "timeout" // 连接、读写或请求超时
+	ErrorTLS     = "tls"     // TLS握手失败
+	ErrorServer  = "5xx"     // 服务端返回5xx状态码
+	ErrorOther   = "other"   // 其他错误，不重试
+)
+
+// RetryPolicy 重试策略，HTTP请求与TCP/UDP请求共用：最大尝试次数与带随机抖动的指数退避，
+// 只重试域名解析失败、连接被拒绝、连接重置、超时、TLS握手失败与5xx响应
+type RetryPolicy struct {
+	MaxAttempts int           // 最大尝试次数（含首次请求），1为不重试
+	BaseDelay   time.Duration // 首次重试的退避时间，之后每次翻倍
+	MaxDelay    time.Duration // 最大退避时间
+}
+
+// Retry 全局重试策略，对应 -retries 与 -retry-delay
+var Retry = RetryPolicy{
+	MaxAttempts: DefaultRetryAttempts,
+	BaseDelay:   DefaultRetryBaseDelay,
+	MaxDelay:    DefaultRetryMaxDelay,
+}
+
+// SetRetryPolicy 设置全局重试策略的最大尝试次数与首次退避时间，参数为0时保持原值
+func SetRetryPolicy(attempts int, baseDelay time.Duration) {
+	if attempts > 0 {
+		Retry.MaxAttempts = attempts
+	}
+	if baseDelay > 0 {
+		Retry.BaseDelay = baseDelay
+	}
+}
+
+// attempts 返回最大尝试次数，不小于1
+func (p RetryPolicy) attempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+// Retryable 判断错误分类是否可以重试
+func (p RetryPolicy) Retryable(class string) bool {
+	switch class {
+	case ErrorDNS, ErrorRefused, ErrorReset, ErrorTimeout, ErrorTLS, ErrorServer:
+		return true
+	}
+	return false
+}
+
+// Backoff 返回第 attempt 次失败后的退避时间，在 delay 的一半到全部之间随机取值，避免大量目标同时重试
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.delay(attempt)
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// delay 返回第 attempt 次失败后的最长退避时间：BaseDelay*2^(attempt-1)，不超过 MaxDelay
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Budget 返回单次请求在全部重试与退避下所需的最长时间，用于设置请求上下文的超时
+func (p RetryPolicy) Budget(timeout time.Duration) time.Duration {
+	budget := timeout
+	for attempt := 1; attempt < p.attempts(); attempt++ {
+		budget += timeout + p.delay(attempt)
+	}
+	return budget
+}
+
+// Do 执行 fn，返回可重试的错误时退避后重试，直到成功、错误不可重试、达到最大尝试次数或 ctx 结束，返回最后一次的错误；
+// errs 不为空时记录每次失败的错误分类与最终失败的错误分类
+func (p RetryPolicy) Do(ctx context.Context, errs *ErrorStats, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		class := ClassifyError(err)
+		errs.Record(class)
+		if attempt >= p.attempts() || !p.Retryable(class) || sleepUntil(ctx, time.Now().Add(p.Backoff(attempt))) != nil {
+			errs.Fail(class)
+			return err
+		}
+	}
+}
+
+// checkRetry 返回 retryablehttp 的重试判断函数：记录失败的错误分类并按策略决定是否重试，
+// 503 已由速率限制器按 Retry-After 退避重试，不再重复重试
+func (p RetryPolicy) checkRetry() func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		class := ClassifyError(err)
+		if err == nil && resp != nil {
+			class = ClassifyStatus(resp.StatusCode)
+		}
+		if class == "" {
+			return false, nil
+		}
+		ErrorStatsFromContext(ctx).Record(class)
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if resp != nil && resp.StatusCode == http.StatusServiceUnavailable {
+			return false, nil
+		}
+		return p.Retryable(class), nil
+	}
+}
+
+// backoff 返回 retryablehttp 的退避函数，attemptNum 从0开始
+func (p RetryPolicy) backoff() func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	return func(_, _ time.Duration, attemptNum int, _ *http.Response) time.Duration {
+		return p.Backoff(attemptNum + 1)
+	}
+}
+
+// ClassifyError 返回请求错误的分类，err 为空时返回空字符串
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var dnsErr *net.DNSError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var netErr net.Error
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorReset
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.As(err, &recordErr), errors.As(err, &alertErr),
+		strings.Contains(msg, "tls: "), strings.Contains(msg, "handshake failure"):
+		return ErrorTLS
+	case strings.Contains(msg, "connection refused"):
+		return ErrorRefused
+	case strings.Contains(msg, "connection reset"), strings.Contains(msg, "broken pipe"):
+		return ErrorReset
+	}
+	return ErrorOther
+}
+
+// ClassifyStatus 返回响应状态码的错误分类，非5xx时返回空字符串
+func ClassifyStatus(code int) string {
+	if code >= 500 && code <= 599 {
+		return ErrorServer
+	}
+	return ""
+}
+
+// ErrorStats 记录单个目标所有请求的失败次数与错误分类，并发安全，可在同一目标的多个请求之间共享；
+// 值为 nil 时所有方法均为空操作
+type ErrorStats struct {
+	mu     sync.Mutex
+	counts map[string]int // 错误分类 → 失败次数（含重试）
+	final  string         // 最近一次重试耗尽后仍失败的错误分类
+}
+
+type errorStatsKey struct{}
+
+// NewErrorStats 创建错误统计
+func NewErrorStats() *ErrorStats {
+	return &ErrorStats{counts: make(map[string]int)}
+}
+
+// WithContext 将错误统计附加到请求上下文，HTTP请求的每次失败由重试判断记录
+func (s *ErrorStats) WithContext(ctx context.Context) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, errorStatsKey{}, s)
+}
+
+// ErrorStatsFromContext 返回上下文中的错误统计，未附加时返回 nil
+func ErrorStatsFromContext(ctx context.Context) *ErrorStats {
+	s, _ := ctx.Value(errorStatsKey{}).(*ErrorStats)
+	return s
+}
+
+// Record 记录一次失败
+func (s *ErrorStats) Record(class string) {
+	if s == nil || class == "" {
+		return
+	}
+	s.mu.Lock()
+	s.counts[class]++
+	s.mu.Unlock()
+}
+
+// Fail 记录一次重试耗尽后仍失败的请求的错误分类
+func (s *ErrorStats) Fail(class string) {
+	if s == nil || class == "" {
+		return
+	}
+	s.mu.Lock()
+	s.final = class
+	s.mu.Unlock()
+}
+
+// Counts 返回按错误分类统计的失败次数，没有失败时返回 nil
+func (s *ErrorStats) Counts() map[string]int {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.counts) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(s.counts))
+	for class, n := range s.counts {
+		counts[class] = n
+	}
+	return counts
+}
+
+// Total 返回失败总次数
+func (s *ErrorStats) Total() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := 0
+	for _, n := range s.counts {
+		total += n
+	}
+	return total
+}
+
+// Final 返回最近一次重试耗尽后仍失败的错误分类，请求均成功时为空
+func (s *ErrorStats) Final() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.final
+}
@@ -0,0 +1,219 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: scheme.go
+    @Date: 2025/6/24 上午10:15*
+*/
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	schemeCacheTTL         = 30 * time.Minute // 协议探测成功的缓存时间
+	schemeNegativeCacheTTL = 30 * time.Second // 协议探测失败的缓存时间，避免对不可达主机重复探测
+	schemeProbeTimeout     = 3 * time.Second  // 单次协议探测的超时时间
+	schemeProbeMaxBody     = 4096             // 识别HTTPS端口错误页时读取的最大响应体
+)
+
+var (
+	schemeCache  sync.Map // 协议探测缓存，host:port（小写）→ *schemeCacheEntry
+	schemeGroup  singleflight.Group
+	schemeHits   atomic.Int64
+	schemeMisses atomic.Int64
+)
+
+// plainHTTPSignatures 向HTTPS端口发送明文HTTP请求时服务端返回的400错误页特征（nginx、Apache）
+var plainHTTPSignatures = [][]byte{
+	[]byte("the plain http request was sent to https port"),
+	[]byte("speaking plain http to an ssl-enabled server port"),
+}
+
+// schemeCacheEntry 协议探测缓存条目
+type schemeCacheEntry struct {
+	scheme  string
+	err     error
+	expires time.Time
+}
+
+// SchemeCacheStats 协议探测缓存统计
+type SchemeCacheStats struct {
+	Hits    int64 // 命中缓存（含等待同一主机进行中的探测）的次数
+	Misses  int64 // 实际发起探测的次数
+	Entries int   // 当前缓存的主机数量
+}
+
+// GetSchemeCacheStats 获取协议探测缓存统计
+func GetSchemeCacheStats() SchemeCacheStats {
+	entries := 0
+	schemeCache.Range(func(_, _ interface{}) bool {
+		entries++
+		return true
+	})
+	return SchemeCacheStats{Hits: schemeHits.Load(), Misses: schemeMisses.Load(), Entries: entries}
+}
+
+// ClearSchemeCache 清空协议探测缓存，统计计数保留
+func ClearSchemeCache() {
+	schemeCache.Range(func(key, _ interface{}) bool {
+		schemeCache.Delete(key)
+		return true
+	})
+}
+
+// RememberScheme 记录带协议URL所在主机使用的协议，后续对同一 host:port 的无协议目标直接复用，不再探测
+func RememberScheme(target string) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	schemeCache.Store(strings.ToLower(u.Host), &schemeCacheEntry{scheme: u.Scheme, expires: time.Now().Add(schemeCacheTTL)})
+}
+
+// CheckProtocol 检查网络通信协议，为无协议的目标补全 http:// 或 https://，探测结果按 host:port 缓存
+func CheckProtocol(host string, proxy string) (string, error) {
+	if len(strings.TrimSpace(host)) == 0 {
+		return "", fmt.Errorf("host %q is empty", host)
+	}
+
+	if strings.HasPrefix(host, HttpPrefix) || strings.HasPrefix(host, HttpsPrefix) {
+		return host, nil
+	}
+
+	u, err := url.Parse(HttpPrefix + host)
+	if err != nil {
+		return "", err
+	}
+
+	scheme, err := detectScheme(strings.ToLower(u.Host), u.Port(), proxy)
+	if err != nil {
+		return "", err
+	}
+	return scheme + "://" + host, nil
+}
+
+// detectScheme 获取 host:port 使用的协议，优先读取缓存，同一主机的并发探测合并为一次
+func detectScheme(hostPort, port, proxy string) (string, error) {
+	if cached, ok := schemeCache.Load(hostPort); ok {
+		entry := cached.(*schemeCacheEntry)
+		if time.Now().Before(entry.expires) {
+			schemeHits.Add(1)
+			return entry.scheme, entry.err
+		}
+		schemeCache.Delete(hostPort)
+	}
+
+	probed := false
+	result, _, _ := schemeGroup.Do(hostPort, func() (interface{}, error) {
+		probed = true
+		schemeMisses.Add(1)
+		scheme, err := probeScheme(hostPort, port, proxy)
+		ttl := schemeCacheTTL
+		if err != nil {
+			ttl = schemeNegativeCacheTTL
+		}
+		entry := &schemeCacheEntry{scheme: scheme, err: err, expires: time.Now().Add(ttl)}
+		schemeCache.Store(hostPort, entry)
+		return entry, nil
+	})
+	if !probed {
+		schemeHits.Add(1)
+	}
+	entry := result.(*schemeCacheEntry)
+	return entry.scheme, entry.err
+}
+
+// probeScheme 探测 host:port 使用的协议：80端口先尝试HTTP，其他端口先尝试HTTPS，失败后尝试另一种协议。
+// TLS端口上的明文HTTP服务会在HTTPS握手失败后由HTTP探测识别；
+// HTTP探测返回400且响应为“明文HTTP请求发送到HTTPS端口”的错误页时判定为HTTPS（如80端口上的TLS服务）
+func probeScheme(hostPort, port, proxy string) (string, error) {
+	schemes := []string{"https", "http"}
+	if port == "80" {
+		schemes = []string{"http", "https"}
+	}
+
+	var lastErr error
+	for _, scheme := range schemes {
+		target := scheme + "://" + hostPort
+		status, _, err := probeTarget(target, proxy, http.MethodHead)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if scheme == "http" && status == http.StatusBadRequest && isPlainHTTPRejected(target, proxy) {
+			return "https", nil
+		}
+		return scheme, nil
+	}
+	return "", fmt.Errorf("检查协议失败: %w", lastErr)
+}
+
+// isPlainHTTPRejected 使用GET请求读取400错误页，判断服务端是否提示明文HTTP请求发送到了HTTPS端口
+func isPlainHTTPRejected(target, proxy string) bool {
+	status, body, err := probeTarget(target, proxy, http.MethodGet)
+	if err != nil || status != http.StatusBadRequest {
+		return false
+	}
+	body = bytes.ToLower(body)
+	for _, signature := range plainHTTPSignatures {
+		if bytes.Contains(body, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// probeTarget 向目标发送不跟随重定向的探测请求，返回状态码与解压后最多 schemeProbeMaxBody 字节的响应体（HEAD请求不读取响应体）
+func probeTarget(target, proxy, method string) (int, []byte, error) {
+	transport, err := createTransport(proxy, target, "", "")
+	if err != nil {
+		return 0, nil, err
+	}
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	release := AcquireHost(target)
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), schemeProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	applyIdentity(req.Header, false)
+	// 部分服务端会对HEAD请求返回响应体，不复用探测连接
+	req.Close = true
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	// 探测请求同样声明了 Accept-Encoding，错误页可能被压缩，需解压后再匹配特征
+	var body []byte
+	if method != http.MethodHead {
+		body, _, _ = ReadBody(resp, schemeProbeMaxBody)
+	}
+	return resp.StatusCode, body, nil
+}
@@ -0,0 +1,209 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: timing.go
+    @Date: 2025/6/14 上午9:30*
+*/
+package network
+
+import (
+	"crypto/tls"
+	"gxx/utils/proto"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Timing 单次请求的各阶段耗时，HTTP请求通过 httptrace 记录，raw/TCP/UDP 请求手动记录
+// 发生重试时各阶段记录最后一次尝试的耗时，总耗时从首次发起请求开始计算
+type Timing struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	DNS          time.Duration // DNS解析耗时
+	Connect      time.Duration // TCP连接建立耗时
+	TLS          time.Duration // TLS握手耗时
+	FirstByte    time.Duration // 收到响应首字节的耗时
+	Total        time.Duration // 读取完响应的总耗时
+}
+
+// NewTiming 创建耗时记录并开始计时
+func NewTiming() *Timing {
+	return &Timing{start: time.Now()}
+}
+
+// Restart 清空已记录的耗时并重新开始计时，在协议探测与等待主机限速结束后、发送请求前调用，排队时间不计入耗时
+func (t *Timing) Restart() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.start = time.Now()
+	t.dnsStart, t.connectStart, t.tlsStart = time.Time{}, time.Time{}, time.Time{}
+	t.DNS, t.Connect, t.TLS, t.FirstByte, t.Total = 0, 0, 0, 0, 0
+	t.mu.Unlock()
+}
+
+// Exclude 将一段等待时间（如传输层内等待速率限制与 429/503 退避）从耗时中扣除
+func (t *Timing) Exclude(wait time.Duration) {
+	if t == nil || wait <= 0 {
+		return
+	}
+	t.mu.Lock()
+	t.start = t.start.Add(wait)
+	t.mu.Unlock()
+}
+
+// timingKey 请求上下文中 Timing 的键
+type timingKey struct{}
+
+// timingFromContext 返回挂载到请求上下文的 Timing，未挂载时返回nil
+func timingFromContext(ctx context.Context) *Timing {
+	t, _ := ctx.Value(timingKey{}).(*Timing)
+	return t
+}
+
+// WithContext 将 httptrace 挂载到请求上下文，Timing 为空时原样返回
+func (t *Timing) WithContext(ctx context.Context) context.Context {
+	if t == nil {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, timingKey{}, t)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.DNS = time.Since(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			t.connectStart = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			t.Connect = time.Since(t.connectStart)
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.TLS = time.Since(t.tlsStart)
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.MarkFirstByte()
+		},
+	})
+}
+
+// MarkConnect 记录连接建立耗时，用于不经过 httptrace 的TCP/UDP连接
+func (t *Timing) MarkConnect(connect time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.Connect = connect
+	t.mu.Unlock()
+}
+
+// MarkTLS 记录TLS握手耗时，用于不经过 httptrace 的TLS连接
+func (t *Timing) MarkTLS(handshake time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.TLS = handshake
+	t.mu.Unlock()
+}
+
+// MarkFirstByte 记录收到响应首字节的时间，仅记录第一次
+func (t *Timing) MarkFirstByte() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.FirstByte == 0 {
+		t.FirstByte = time.Since(t.start)
+	}
+	t.mu.Unlock()
+}
+
+// Done 结束计时，记录总耗时，仅第一次调用生效；未记录首字节时以总耗时代替
+func (t *Timing) Done() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.Total == 0 {
+		t.Total = time.Since(t.start)
+	}
+	if t.FirstByte == 0 {
+		t.FirstByte = t.Total
+	}
+	t.mu.Unlock()
+}
+
+// timingBody 读取到响应体末尾时结束计时
+type timingBody struct {
+	io.ReadCloser
+	timing *Timing
+}
+
+// Read 读取响应体，遇到EOF时记录总耗时
+func (b *timingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.timing.Done()
+	}
+	return n, err
+}
+
+// WrapBody 包装响应体，响应体读取完毕时自动结束计时，适用于响应体由其他函数读取的场景
+func (t *Timing) WrapBody(body io.ReadCloser) io.ReadCloser {
+	if t == nil || body == nil {
+		return body
+	}
+	return &timingBody{ReadCloser: body, timing: t}
+}
+
+// Latency 返回首字节耗时（毫秒），用于 response.latency
+func (t *Timing) Latency() int64 {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.FirstByte.Milliseconds()
+}
+
+// ToProto 转换为 proto.TimingType，Timing 为空时返回空的耗时信息
+func (t *Timing) ToProto() *proto.TimingType {
+	if t == nil {
+		return &proto.TimingType{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &proto.TimingType{
+		Dns:       t.DNS.Milliseconds(),
+		Connect:   t.Connect.Milliseconds(),
+		Tls:       t.TLS.Milliseconds(),
+		FirstByte: t.FirstByte.Milliseconds(),
+		Total:     t.Total.Milliseconds(),
+	}
+}
@@ -0,0 +1,179 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: tls_fingerprint.go
+    @Date: 2025/6/20 上午10:15*
+*/
+package network
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	utls "github.com/refraction-networking/utls"
+	"golang.org/x/net/context"
+)
+
+// TLS客户端指纹（ClientHello）预设
+const (
+	TLSFingerprintGo         = "go"         // Go 标准库的 ClientHello
+	TLSFingerprintChrome     = "chrome"     // 模拟 Chrome 浏览器
+	TLSFingerprintFirefox    = "firefox"    // 模拟 Firefox 浏览器
+	TLSFingerprintSafari     = "safari"     // 模拟 Safari 浏览器
+	TLSFingerprintRandomized = "randomized" // 每个连接随机生成 ClientHello
+)
+
+// TLSFingerprint 全局TLS客户端指纹，指纹规则未配置 tls-fingerprint 时使用
+var TLSFingerprint = TLSFingerprintGo
+
+// browserHelloIDs 浏览器预设对应的 uTLS ClientHello
+var browserHelloIDs = map[string]utls.ClientHelloID{
+	TLSFingerprintChrome:  utls.HelloChrome_Auto,
+	TLSFingerprintFirefox: utls.HelloFirefox_Auto,
+	TLSFingerprintSafari:  utls.HelloSafari_Auto,
+}
+
+// SetTLSFingerprint 设置全局TLS客户端指纹，name 为空时使用 go
+func SetTLSFingerprint(name string) error {
+	if name == "" {
+		TLSFingerprint = TLSFingerprintGo
+		return nil
+	}
+	fingerprint, err := ResolveTLSFingerprint(name)
+	if err != nil {
+		return err
+	}
+	TLSFingerprint = fingerprint
+	return nil
+}
+
+// ResolveTLSFingerprint 校验TLS客户端指纹名称，name 为空时返回全局配置
+func ResolveTLSFingerprint(name string) (string, error) {
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case "":
+		return TLSFingerprint, nil
+	case TLSFingerprintGo, TLSFingerprintChrome, TLSFingerprintFirefox, TLSFingerprintSafari, TLSFingerprintRandomized:
+		return name, nil
+	default:
+		return "", fmt.Errorf("未知的TLS客户端指纹: %s，可选：%s、%s、%s、%s、%s", name,
+			TLSFingerprintGo, TLSFingerprintChrome, TLSFingerprintFirefox, TLSFingerprintSafari, TLSFingerprintRandomized)
+	}
+}
+
+// utlsHandshake 在已建立的连接上使用指定的 ClientHello 完成TLS握手，握手失败时关闭连接；
+// h1Only 为true时 ALPN 只声明 http/1.1，用于只能处理 HTTP/1.1 的传输层与 ssl 连接，
+// ALPN 的取值不参与 JA3 计算，浏览器指纹保持不变
+func utlsHandshake(ctx context.Context, conn net.Conn, serverName, fingerprint string, h1Only bool) (net.Conn, error) {
+	config := &utls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		MinVersion:         utls.VersionTLS10,
+	}
+
+	var uconn *utls.UConn
+	if fingerprint == TLSFingerprintRandomized {
+		id := utls.HelloRandomizedALPN
+		if h1Only {
+			id = utls.HelloRandomizedNoALPN
+		}
+		uconn = utls.UClient(conn, config, id)
+	} else {
+		id, ok := browserHelloIDs[fingerprint]
+		if !ok {
+			_ = conn.Close()
+			return nil, fmt.Errorf("未知的TLS客户端指纹: %s", fingerprint)
+		}
+		spec, err := utls.UTLSIdToSpec(id)
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("生成 %s ClientHello 失败: %v", fingerprint, err)
+		}
+		if h1Only {
+			for _, ext := range spec.Extensions {
+				if alpn, ok := ext.(*utls.ALPNExtension); ok {
+					alpn.AlpnProtocols = []string{"http/1.1"}
+				}
+			}
+		}
+		uconn = utls.UClient(conn, config, utls.HelloCustom)
+		if err := uconn.ApplyPreset(&spec); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("应用 %s ClientHello 失败: %v", fingerprint, err)
+		}
+	}
+
+	if err := uconn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return uconn, nil
+}
+
+// tlsDial 返回先通过 dial 建立连接（直连或经过代理）再完成TLS握手的拨号函数，serverName 返回连接地址使用的 SNI，
+// fingerprint 为 go 时使用标准库握手，否则使用 uTLS
+func tlsDial(dial dialFunc, fingerprint string, serverName func(addr string) string, h1Only bool) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if fingerprint == TLSFingerprintGo {
+			return stdHandshake(ctx, conn, serverName(addr), h1Only)
+		}
+		return utlsHandshake(ctx, conn, serverName(addr), fingerprint, h1Only)
+	}
+}
+
+// stdHandshake 使用标准库与全局TLS配置完成握手，握手失败时关闭连接
+func stdHandshake(ctx context.Context, conn net.Conn, serverName string, h1Only bool) (net.Conn, error) {
+	config := tlsConfig.Clone()
+	config.ServerName = serverName
+	config.NextProtos = []string{"h2", "http/1.1"}
+	if h1Only {
+		config.NextProtos = []string{"http/1.1"}
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// addrHost 返回连接地址中的主机名
+func addrHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// ConnTLSState 返回连接的TLS状态，支持标准库与 uTLS 连接，非TLS连接返回空
+func ConnTLSState(conn net.Conn) *tls.ConnectionState {
+	switch c := conn.(type) {
+	case *tls.Conn:
+		state := c.ConnectionState()
+		return &state
+	case *utls.UConn:
+		state := c.ConnectionState()
+		return &tls.ConnectionState{
+			Version:                     state.Version,
+			HandshakeComplete:           state.HandshakeComplete,
+			DidResume:                   state.DidResume,
+			CipherSuite:                 state.CipherSuite,
+			NegotiatedProtocol:          state.NegotiatedProtocol,
+			NegotiatedProtocolIsMutual:  state.NegotiatedProtocolIsMutual,
+			ServerName:                  state.ServerName,
+			PeerCertificates:            state.PeerCertificates,
+			VerifiedChains:              state.VerifiedChains,
+			SignedCertificateTimestamps: state.SignedCertificateTimestamps,
+			OCSPResponse:                state.OCSPResponse,
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,391 @@
+/*
+  - Package request
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: transport.go
+    @Date: 2025/6/18 下午3:40*
+*/
+package network
+
+import (
+	"crypto/tls"
+	"fmt"
+	"gxx/utils/logger"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chainreactors/proxyclient"
+	"golang.org/x/net/context"
+	"golang.org/x/net/http2"
+)
+
+const (
+	transportIdleTTL      = 2 * time.Minute  // 目标连接池空闲超过该时间后回收
+	maxCachedTransports   = 100              // 连接池缓存上限，超过时回收全部连接池
+	maxIdleConnsPerTarget = 50               // 单个目标保留的空闲连接数，与默认单主机并发数一致
+	idleConnTimeout       = 30 * time.Second // 空闲连接保留时间，短于多数服务端的 keep-alive 超时
+	h2ProbeTimeout        = 3 * time.Second  // h2c 与 uTLS h2 探测超时时间
+)
+
+// dialFunc 建立连接的函数，直连或经过代理
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// targetTransport 单个目标（代理 + TLS客户端指纹 + 虚拟主机 + 协议 + 主机端口）独立的连接池
+// 默认复用 keep-alive 连接，HTTPS 通过 ALPN 协商 HTTP/2，开启 h2c 探测时支持 h2c 的 HTTP 目标使用 HTTP/2 明文；
+// 复用的连接出错时（如服务端异常关闭连接、在空闲连接上返回多余数据），该目标回退为不复用连接的 HTTP/1.1；
+// 配置虚拟主机时，发往目标地址的请求使用虚拟主机作为 Host 与 SNI，发往虚拟主机名的请求改为连接目标地址
+type targetTransport struct {
+	key         string
+	origin      string // 目标地址 scheme://host:port，为空时不探测 h2c
+	fingerprint string // TLS客户端指纹，非 go 时使用 uTLS 握手
+	vhost       string // 虚拟主机，可带端口
+	vhostName   string // 虚拟主机的主机名，用作 SNI
+	pinHost     string // 目标地址的主机名或IP，虚拟主机请求实际连接的地址
+	dial        dialFunc
+	initOnce    sync.Once
+	mu          sync.RWMutex
+	rt          http.RoundTripper
+	h2          http.RoundTripper // 目标支持 h2c 或 uTLS 协商到 h2 时使用，仅用于发往 origin 的请求
+	fallback    bool              // 是否已回退为短连接
+	lastUsed    atomic.Int64
+}
+
+// createTransport 返回目标对应的连接池，proxy 为代理配置（单个代理、代理列表或代理文件），
+// fingerprint 为TLS客户端指纹，为空时使用全局配置，vhost 为虚拟主机，为空时使用目标地址；
+// 按代理池策略选择代理后，同一代理、TLS客户端指纹、虚拟主机与目标复用同一个连接池
+func createTransport(proxy, target, fingerprint, vhost string) (http.RoundTripper, error) {
+	fingerprint, err := ResolveTLSFingerprint(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	proxyURL, err := SelectProxy(proxy, target)
+	if err != nil {
+		return nil, err
+	}
+	origin := targetOrigin(target)
+	key := proxyURL + "|" + fingerprint + "|" + vhost + "|" + origin
+	if cached, found := transportCache.Load(key); found {
+		return cached.(*targetTransport), nil
+	}
+
+	dial := dialFunc((&net.Dialer{Timeout: DefaultTimeout, KeepAlive: 30 * time.Second}).DialContext)
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("代理地址解析失败: %v", err)
+		}
+		dialer, err := proxyclient.NewClient(u)
+		if err != nil {
+			return nil, fmt.Errorf("创建代理客户端失败: %v", err)
+		}
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil && ctx.Err() == nil {
+				ReportProxyFailure(proxyURL)
+			}
+			return conn, err
+		}
+	}
+	// 配置了 -resolve 或 -resolver 时在本地解析目标主机名，经过代理时同样生效
+	dial = resolveDial(dial)
+
+	t := &targetTransport{key: key, origin: origin, fingerprint: fingerprint, dial: dial}
+	if vhost != "" {
+		if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+			t.vhost, t.vhostName, t.pinHost = vhost, addrHost(vhost), u.Hostname()
+		}
+	}
+	t.lastUsed.Store(time.Now().UnixNano())
+	cached, _ := transportCache.LoadOrStore(key, t)
+	return cached.(*targetTransport), nil
+}
+
+// targetOrigin 返回目标的 scheme://host:port，无法解析时返回空
+func targetOrigin(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// newHttpTransport 创建HTTP/1.1传输层，keepAlive 为true时复用连接并通过 ALPN 协商 HTTP/2；
+// fingerprint 非 go 时使用 uTLS 握手，此时 net/http 无法识别协商结果，ALPN 只声明 http/1.1，HTTP/2 由 init 探测后单独处理；
+// 配置虚拟主机时由自定义TLS拨号设置 SNI
+func (t *targetTransport) newHttpTransport(keepAlive bool) *http.Transport {
+	transport := &http.Transport{
+		DialContext:         t.dial,
+		TLSClientConfig:     tlsConfig.Clone(), // 协商 HTTP/2 时会修改 NextProtos，不能共用
+		MaxIdleConns:        maxIdleConnsPerTarget,
+		MaxIdleConnsPerHost: maxIdleConnsPerTarget,
+		IdleConnTimeout:     idleConnTimeout,
+		DisableKeepAlives:   !keepAlive,
+		ForceAttemptHTTP2:   keepAlive && !DisableHTTP2,
+		DisableCompression:  true, // 响应体由 ReadBody 按 Content-Encoding 解压，保留原始响应头
+	}
+	switch {
+	case t.fingerprint != TLSFingerprintGo:
+		transport.DialTLSContext = tlsDial(t.dial, t.fingerprint, t.serverName, true)
+		transport.ForceAttemptHTTP2 = false
+	case t.vhost != "":
+		// 标准库握手返回 *tls.Conn，net/http 可识别 ALPN 协商到的 h2
+		transport.DialTLSContext = tlsDial(t.dial, t.fingerprint, t.serverName, !transport.ForceAttemptHTTP2)
+	}
+	return transport
+}
+
+// newUTLSH2Transport 创建使用 uTLS 握手的 HTTP/2 传输层
+func (t *targetTransport) newUTLSH2Transport() *http2.Transport {
+	dialTLS := tlsDial(t.dial, t.fingerprint, t.serverName, false)
+	return &http2.Transport{
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dialTLS(ctx, network, addr)
+		},
+		ReadIdleTimeout:    idleConnTimeout,
+		DisableCompression: true,
+	}
+}
+
+// serverName 返回连接地址使用的 SNI，配置虚拟主机时连接目标地址使用虚拟主机名
+func (t *targetTransport) serverName(addr string) string {
+	host := addrHost(addr)
+	if t.vhost != "" && strings.EqualFold(host, t.pinHost) {
+		return t.vhostName
+	}
+	return host
+}
+
+// pinVhost 改写虚拟主机目标的请求：发往目标地址且未指定 Host 的请求使用虚拟主机作为 Host，
+// 发往虚拟主机名的请求（如重定向）改为连接目标地址并保留原 Host
+func (t *targetTransport) pinVhost(req *http.Request) *http.Request {
+	host := req.URL.Hostname()
+	switch {
+	case strings.EqualFold(host, t.vhostName):
+		pinned := req.Clone(req.Context())
+		pinned.Host = req.URL.Host
+		pinned.URL.Host = t.pinHost
+		if port := req.URL.Port(); port != "" {
+			pinned.URL.Host = net.JoinHostPort(t.pinHost, port)
+		} else if strings.Contains(t.pinHost, ":") {
+			pinned.URL.Host = "[" + t.pinHost + "]"
+		}
+		return pinned
+	case strings.EqualFold(host, t.pinHost) && (req.Host == "" || req.Host == req.URL.Host):
+		pinned := req.Clone(req.Context())
+		pinned.Host = t.hostHeader(req.URL)
+		return pinned
+	}
+	return req
+}
+
+// hostHeader 返回发往目标地址的请求使用的 Host
+func (t *targetTransport) hostHeader(u *url.URL) string {
+	return VhostHeader(u, t.vhost)
+}
+
+// VhostHeader 返回虚拟主机请求使用的 Host，虚拟主机未带端口且目标使用非默认端口时补充目标端口
+func VhostHeader(u *url.URL, vhost string) string {
+	port := u.Port()
+	if addrHost(vhost) != vhost || port == "" ||
+		(u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		return vhost
+	}
+	return net.JoinHostPort(vhost, port)
+}
+
+// newH2cTransport 创建 HTTP/2 明文（prior knowledge）传输层
+func newH2cTransport(dial dialFunc) *http2.Transport {
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		},
+		ReadIdleTimeout:    idleConnTimeout,
+		DisableCompression: true,
+	}
+}
+
+// init 首次使用时创建传输层，开启 h2c 探测时先探测 HTTP 目标是否支持 h2c，
+// 使用 uTLS 时先握手一次探测 HTTPS 目标是否协商 h2
+func (t *targetTransport) init() {
+	if DisableKeepAlives {
+		t.rt, t.fallback = t.newHttpTransport(false), true
+		return
+	}
+	t.rt = t.newHttpTransport(true)
+	if EnableH2C && strings.HasPrefix(t.origin, HttpPrefix) {
+		h2c := newH2cTransport(t.dial)
+		if probeH2C(h2c, t.origin, t.vhost) {
+			logger.Debug(fmt.Sprintf("目标 %s 支持h2c，使用HTTP/2明文通信", t.origin))
+			t.h2 = h2c
+			return
+		}
+		h2c.CloseIdleConnections()
+	}
+	if t.fingerprint != TLSFingerprintGo && !DisableHTTP2 && strings.HasPrefix(t.origin, HttpsPrefix) {
+		if t.probeUTLSH2() {
+			logger.Debug(fmt.Sprintf("目标 %s 协商到h2，使用 %s TLS指纹的HTTP/2通信", t.origin, t.fingerprint))
+			t.h2 = t.newUTLSH2Transport()
+		}
+	}
+}
+
+// RoundTrip 发送请求，复用的连接出错时回退为短连接，由上层的重试机制重新发送
+func (t *targetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.initOnce.Do(t.init)
+	t.lastUsed.Store(time.Now().UnixNano())
+	if t.vhost != "" {
+		req = t.pinVhost(req)
+	}
+
+	t.mu.RLock()
+	rt, h2, fallback := t.rt, t.h2, t.fallback
+	t.mu.RUnlock()
+	// 重定向到其他地址时不使用 HTTP/2 传输层
+	if !fallback && h2 != nil && targetOrigin(req.URL.String()) == t.origin {
+		rt = h2
+	}
+
+	var (
+		reused atomic.Bool
+		conn   atomic.Value
+	)
+	ctx := httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused.Store(info.Reused)
+			if info.Conn != nil {
+				conn.Store(info.Conn)
+			}
+		},
+	})
+	resp, err := rt.RoundTrip(req.WithContext(ctx))
+	if err != nil && !fallback && reused.Load() && req.Context().Err() == nil {
+		t.disableReuse(err)
+	}
+	// uTLS 连接不是 *tls.Conn，net/http 不会填充 resp.TLS，由连接状态补充
+	if resp != nil && resp.TLS == nil {
+		if c, ok := conn.Load().(net.Conn); ok {
+			resp.TLS = ConnTLSState(c)
+		}
+	}
+	return resp, err
+}
+
+// disableReuse 目标回退为不复用连接的 HTTP/1.1
+func (t *targetTransport) disableReuse(err error) {
+	t.mu.Lock()
+	if t.fallback {
+		t.mu.Unlock()
+		return
+	}
+	old, h2 := t.rt, t.h2
+	t.rt, t.h2, t.fallback = t.newHttpTransport(false), nil, true
+	t.mu.Unlock()
+	closeIdle(old)
+	closeIdle(h2)
+	logger.Debug(fmt.Sprintf("目标 %s 复用连接出错，回退为短连接：%v", t.key, err))
+}
+
+// CloseIdleConnections 关闭连接池中的空闲连接
+func (t *targetTransport) CloseIdleConnections() {
+	t.mu.RLock()
+	rt, h2 := t.rt, t.h2
+	t.mu.RUnlock()
+	closeIdle(rt)
+	closeIdle(h2)
+}
+
+// ProxyTransport 返回目标对应的传输层，供不经过 SendRequestHttp 的请求使用代理池与连接复用
+func ProxyTransport(proxy, target string) http.RoundTripper {
+	transport, err := createTransport(proxy, target, "", "")
+	if err != nil {
+		logger.Debug(fmt.Sprintf("创建传输层失败: %v", err))
+		return http.DefaultTransport
+	}
+	return transport
+}
+
+// closeIdle 关闭传输层的空闲连接
+func closeIdle(rt http.RoundTripper) {
+	if c, ok := rt.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// probeH2C 通过 HTTP/2 明文先验知识发送请求，目标正常响应时说明支持 h2c，host 不为空时作为请求的 Host
+func probeH2C(rt http.RoundTripper, origin, host string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), h2ProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, origin+"/", nil)
+	if err != nil {
+		return false
+	}
+	if host != "" {
+		req.Host = host
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("目标 %s 不支持h2c：%v", origin, err))
+		return false
+	}
+	_ = resp.Body.Close()
+	return true
+}
+
+// probeUTLSH2 使用 uTLS 握手一次，判断 HTTPS 目标是否通过 ALPN 协商 h2
+func (t *targetTransport) probeUTLSH2() bool {
+	u, err := url.Parse(t.origin)
+	if err != nil {
+		return false
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h2ProbeTimeout)
+	defer cancel()
+	conn, err := tlsDial(t.dial, t.fingerprint, t.serverName, false)(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		logger.Debug(fmt.Sprintf("目标 %s 探测h2失败：%v", t.origin, err))
+		return false
+	}
+	defer conn.Close()
+	state := ConnTLSState(conn)
+	return state != nil && state.NegotiatedProtocol == http2.NextProtoTLS
+}
+
+// cleanupTransportCache 定期回收空闲的连接池，缓存超过上限时回收全部连接池
+func cleanupTransportCache() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		var count int
+		transportCache.Range(func(key, value interface{}) bool {
+			t := value.(*targetTransport)
+			if now.Sub(time.Unix(0, t.lastUsed.Load())) > transportIdleTTL {
+				transportCache.Delete(key)
+				t.CloseIdleConnections()
+			} else {
+				count++
+			}
+			return true
+		})
+
+		if count > maxCachedTransports {
+			transportCache.Range(func(key, value interface{}) bool {
+				transportCache.Delete(key)
+				value.(*targetTransport).CloseIdleConnections()
+				return true
+			})
+			logger.Debug("transport缓存过大，已重置")
+		}
+	}
+}
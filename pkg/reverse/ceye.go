@@ -0,0 +1,53 @@
+/*
+  - Package reverse
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: ceye.go
+    @Date: 2025/6/16 上午10:10*
+*/
+package reverse
+
+import (
+	"bytes"
+	"fmt"
+	"gxx/pkg/network"
+	"gxx/utils/proto"
+	"time"
+)
+
+const ceyePollInterval = 2 * time.Second // ceye 查询间隔，避免触发平台限频
+
+// CeyeProvider ceye.io 反连平台
+type CeyeProvider struct {
+	conf CeyeConfig
+}
+
+// Name 返回平台名称
+func (p *CeyeProvider) Name() string {
+	return ProviderCeye
+}
+
+// New 生成 http://标识.ceye域名 形式的反连地址
+func (p *CeyeProvider) New() *proto.Reverse {
+	domain := fmt.Sprintf("%s.%s", newToken(tokenLength), p.conf.Domain)
+	return buildReverse("http://"+domain, domain, domain, false)
+}
+
+// Poll 通过ceye API查询DNS记录，未配置API Token时直接返回false
+func (p *CeyeProvider) Poll(r *proto.Reverse, timeout time.Duration) bool {
+	if p.conf.ApiKey == "" || r.GetDomain() == "" {
+		return false
+	}
+	urlStr := fmt.Sprintf("http://api.ceye.io/v1/records?token=%s&type=dns&filter=%s", p.conf.ApiKey, domainToken(r))
+	return pollUntil(timeout, ceyePollInterval, func() bool {
+		resp, err := network.ReverseGet(urlStr)
+		if err != nil {
+			return false
+		}
+		// api返回结果不为空
+		return !bytes.Contains(resp, []byte(`"data": []`)) && bytes.Contains(resp, []byte(`{"code": 200`))
+	})
+}
+
+// Close ceye 平台无需释放资源
+func (p *CeyeProvider) Close() {}
@@ -0,0 +1,86 @@
+/*
+  - Package reverse
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: config.go
+    @Date: 2025/6/16 上午9:40*
+*/
+package reverse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// 反连平台名称
+const (
+	ProviderCeye       = "ceye"
+	ProviderInteractsh = "interactsh"
+	ProviderHttp       = "http"
+	ProviderLocal      = "local"
+)
+
+// Config 反连配置，provider 用于DNS/HTTP反连，jndi-provider 用于LDAP反连
+type Config struct {
+	Provider     string           `yaml:"provider" toml:"provider"`           // 反连平台：ceye、interactsh、http、local
+	JndiProvider string           `yaml:"jndi-provider" toml:"jndi-provider"` // JNDI反连平台：http、local，为空时自动选择
+	Ceye         CeyeConfig       `yaml:"ceye" toml:"ceye"`
+	Interactsh   InteractshConfig `yaml:"interactsh" toml:"interactsh"`
+	Http         HttpConfig       `yaml:"http" toml:"http"`
+	Jndi         HttpConfig       `yaml:"jndi" toml:"jndi"` // jndi-provider 为 http 时使用
+	Local        LocalConfig      `yaml:"local" toml:"local"`
+}
+
+// CeyeConfig ceye.io 平台配置
+type CeyeConfig struct {
+	ApiKey string `yaml:"api-key" toml:"api-key"` // ceye API Token
+	Domain string `yaml:"domain" toml:"domain"`   // ceye 分配的域名，如 xxxx.ceye.io
+}
+
+// InteractshConfig interactsh 兼容服务配置
+type InteractshConfig struct {
+	Server string `yaml:"server" toml:"server"` // 服务地址，如 https://oast.fun
+	Token  string `yaml:"token" toml:"token"`   // 服务端开启认证时使用的Token
+}
+
+// HttpConfig 自定义HTTP API反连平台配置，url 与 api 中的 {{token}} 会替换为反连标识，{{domain}} 替换为 标识.domain
+type HttpConfig struct {
+	Domain string `yaml:"domain" toml:"domain"` // 反连根域名，配置后标识位于域名第一段，否则位于url路径最后一段
+	URL    string `yaml:"url" toml:"url"`       // 反连地址模板，如 http://1.2.3.4:8080/{{token}}
+	Api    string `yaml:"api" toml:"api"`       // 查询地址模板，如 http://1.2.3.4:8081/?api={{token}}
+	Match  string `yaml:"match" toml:"match"`   // 查询结果包含该字符串时视为命中，为空时查询结果包含标识即命中
+}
+
+// LoadConfig 从YAML或TOML文件读取反连配置，扩展名为 .toml 时按TOML解析
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取反连配置文件失败: %v", err)
+	}
+	conf := &Config{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, conf)
+	} else {
+		err = yaml.Unmarshal(data, conf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析反连配置文件失败: %v", err)
+	}
+	return conf, nil
+}
+
+// jndiProvider 返回JNDI反连平台名称，未配置时启用了本地LDAP监听则使用本地服务，否则使用自定义HTTP API
+func (c *Config) jndiProvider() string {
+	if c.JndiProvider != "" {
+		return c.JndiProvider
+	}
+	if c.Provider == ProviderLocal && c.Local.LdapAddr != "" {
+		return ProviderLocal
+	}
+	return ProviderHttp
+}
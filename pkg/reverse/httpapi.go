@@ -0,0 +1,81 @@
+/*
+  - Package reverse
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: httpapi.go
+    @Date: 2025/6/16 上午10:30*
+*/
+package reverse
+
+import (
+	"bytes"
+	"fmt"
+	"gxx/pkg/network"
+	"gxx/utils/proto"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const httpPollInterval = time.Second // 自定义HTTP API查询间隔
+
+// HttpProvider 自定义HTTP API反连平台，兼容 JNDIExploit 等提供查询接口的反连工具
+type HttpProvider struct {
+	conf HttpConfig
+}
+
+// Name 返回平台名称
+func (p *HttpProvider) Name() string {
+	return ProviderHttp
+}
+
+// New 按url模板生成反连地址，配置了根域名时 domain 为 标识.根域名
+func (p *HttpProvider) New() *proto.Reverse {
+	token := newToken(tokenLength)
+	domain := ""
+	if p.conf.Domain != "" {
+		domain = fmt.Sprintf("%s.%s", token, p.conf.Domain)
+	}
+	urlStr := p.conf.URL
+	if urlStr == "" {
+		urlStr = "http://{{domain}}/{{token}}"
+	}
+	urlStr = p.render(urlStr, token, domain)
+	u, _ := url.Parse(urlStr)
+	ip := ""
+	if u != nil {
+		ip = u.Hostname()
+	}
+	return buildReverse(urlStr, domain, ip, false)
+}
+
+// Poll 轮询查询接口，返回内容包含 match（未配置时为反连标识）即视为命中
+func (p *HttpProvider) Poll(r *proto.Reverse, timeout time.Duration) bool {
+	if p.conf.Api == "" {
+		return false
+	}
+	token := pathToken(r, false)
+	if p.conf.Domain != "" {
+		token = domainToken(r)
+	}
+	if token == "" {
+		return false
+	}
+	match := p.conf.Match
+	if match == "" {
+		match = token
+	}
+	apiURL := p.render(p.conf.Api, token, r.GetDomain())
+	return pollUntil(timeout, httpPollInterval, func() bool {
+		resp, err := network.ReverseGet(apiURL)
+		return err == nil && bytes.Contains(resp, []byte(match))
+	})
+}
+
+// Close 自定义HTTP API平台无需释放资源
+func (p *HttpProvider) Close() {}
+
+// render 替换模板中的 {{token}} 与 {{domain}}
+func (p *HttpProvider) render(tpl, token, domain string) string {
+	return strings.NewReplacer("{{token}}", token, "{{domain}}", domain).Replace(tpl)
+}
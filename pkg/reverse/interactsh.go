@@ -0,0 +1,236 @@
+/*
+  - Package reverse
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: interactsh.go
+    @Date: 2025/6/16 上午11:00*
+*/
+package reverse
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"gxx/utils/logger"
+	"gxx/utils/proto"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	interactshCorrelationLength = 20               // correlation-id 长度，与服务端默认配置一致
+	interactshNonceLength       = 13               // 子域名中 correlation-id 之后的随机部分长度
+	interactshPollInterval      = time.Second      // 查询间隔，多个规则共享同一次查询
+	interactshTimeout           = 10 * time.Second // 注册与查询请求超时时间
+)
+
+// InteractshProvider interactsh 兼容的反连平台，注册一次 correlation-id 后所有反连地址共享查询结果
+type InteractshProvider struct {
+	conf          InteractshConfig
+	host          string
+	client        *http.Client
+	key           *rsa.PrivateKey
+	correlationID string
+	secret        string
+	registerOnce  sync.Once
+	registerErr   error
+	mu            sync.Mutex
+	lastPoll      time.Time
+	hits          map[string]struct{} // 已收到交互的 unique-id
+}
+
+// NewInteractshProvider 创建 interactsh 反连平台，首次生成反连地址时才向服务端注册
+func NewInteractshProvider(conf InteractshConfig) (*InteractshProvider, error) {
+	if conf.Server == "" {
+		return nil, errors.New("未配置interactsh服务地址")
+	}
+	if !strings.Contains(conf.Server, "://") {
+		conf.Server = "https://" + conf.Server
+	}
+	conf.Server = strings.TrimRight(conf.Server, "/")
+	u, err := url.Parse(conf.Server)
+	if err != nil {
+		return nil, fmt.Errorf("interactsh服务地址无效: %v", err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("生成interactsh密钥失败: %v", err)
+	}
+	return &InteractshProvider{
+		conf:          conf,
+		host:          u.Hostname(),
+		client:        &http.Client{Timeout: interactshTimeout},
+		key:           key,
+		correlationID: newToken(interactshCorrelationLength),
+		secret:        newToken(32),
+		hits:          make(map[string]struct{}),
+	}, nil
+}
+
+// Name 返回平台名称
+func (p *InteractshProvider) Name() string {
+	return ProviderInteractsh
+}
+
+// New 生成 http://correlation-id+随机串.服务域名 形式的反连地址
+func (p *InteractshProvider) New() *proto.Reverse {
+	p.registerOnce.Do(func() {
+		p.registerErr = p.register()
+		if p.registerErr != nil {
+			logger.Error(fmt.Sprintf("interactsh注册失败: %v", p.registerErr))
+		}
+	})
+	domain := fmt.Sprintf("%s%s.%s", p.correlationID, newToken(interactshNonceLength), p.host)
+	return buildReverse("http://"+domain, domain, "", true)
+}
+
+// Poll 轮询服务端交互记录，直到出现该反连地址的交互或超时
+func (p *InteractshProvider) Poll(r *proto.Reverse, timeout time.Duration) bool {
+	if p.registerErr != nil {
+		return false
+	}
+	token := domainToken(r)
+	return pollUntil(timeout, interactshPollInterval, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := p.hits[token]; ok {
+			return true
+		}
+		// 多个规则并发等待时，间隔内只向服务端查询一次
+		if time.Since(p.lastPoll) < interactshPollInterval {
+			return false
+		}
+		p.lastPoll = time.Now()
+		if err := p.poll(); err != nil {
+			logger.Debug(fmt.Sprintf("interactsh查询失败: %v", err))
+		}
+		_, ok := p.hits[token]
+		return ok
+	})
+}
+
+// Close 向服务端注销 correlation-id
+func (p *InteractshProvider) Close() {
+	// 未生成过反连地址时无需注销
+	p.registerOnce.Do(func() { p.registerErr = errors.New("未注册") })
+	if p.registerErr != nil {
+		return
+	}
+	body := map[string]string{"correlation-id": p.correlationID, "secret-key": p.secret}
+	if _, err := p.do(http.MethodPost, "/deregister", body); err != nil {
+		logger.Debug(fmt.Sprintf("interactsh注销失败: %v", err))
+	}
+}
+
+// register 上传公钥并注册 correlation-id
+func (p *InteractshProvider) register() error {
+	pub, err := x509.MarshalPKIXPublicKey(&p.key.PublicKey)
+	if err != nil {
+		return err
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pub})
+	body := map[string]string{
+		"public-key":     base64.StdEncoding.EncodeToString(pemKey),
+		"secret-key":     p.secret,
+		"correlation-id": p.correlationID,
+	}
+	_, err = p.do(http.MethodPost, "/register", body)
+	return err
+}
+
+// poll 拉取并解密交互记录，记录到 hits 中，调用方需持有锁
+func (p *InteractshProvider) poll() error {
+	data, err := p.do(http.MethodGet, fmt.Sprintf("/poll?id=%s&secret=%s", p.correlationID, p.secret), nil)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Data   []string `json:"data"`
+		AESKey string   `json:"aes_key"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("解析查询结果失败: %v", err)
+	}
+	if len(result.Data) == 0 {
+		return nil
+	}
+	encKey, err := base64.StdEncoding.DecodeString(result.AESKey)
+	if err != nil {
+		return err
+	}
+	aesKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, p.key, encKey, nil)
+	if err != nil {
+		return fmt.Errorf("解密aes_key失败: %v", err)
+	}
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return err
+	}
+	for _, item := range result.Data {
+		raw, err := base64.StdEncoding.DecodeString(item)
+		if err != nil || len(raw) < aes.BlockSize {
+			continue
+		}
+		plain := make([]byte, len(raw)-aes.BlockSize)
+		cipher.NewCFBDecrypter(block, raw[:aes.BlockSize]).XORKeyStream(plain, raw[aes.BlockSize:])
+		var interaction struct {
+			Protocol      string `json:"protocol"`
+			UniqueID      string `json:"unique-id"`
+			RemoteAddress string `json:"remote-address"`
+		}
+		if err := json.Unmarshal(bytes.TrimSpace(plain), &interaction); err != nil {
+			continue
+		}
+		p.hits[strings.ToLower(interaction.UniqueID)] = struct{}{}
+		logger.Debug(fmt.Sprintf("收到%s反连：%s 来源：%s", interaction.Protocol, interaction.UniqueID, interaction.RemoteAddress))
+	}
+	return nil
+}
+
+// do 向interactsh服务端发送请求，body 不为空时以JSON发送
+func (p *InteractshProvider) do(method, path string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, p.conf.Server+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.conf.Token != "" {
+		req.Header.Set("Authorization", p.conf.Token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -0,0 +1,457 @@
+/*
+  - Package reverse
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: local.go
+    @Date: 2025/6/15 上午10:20*
+*/
+package reverse
+
+import (
+	"errors"
+	"fmt"
+	"gxx/utils/logger"
+	"gxx/utils/proto"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	defaultLocalDomain = "gxx.local"      // 未配置域名时使用的默认根域名
+	tokenLength        = 12               // 反连标识长度
+	tokenTTL           = 10 * time.Minute // 反连标识的保留时间
+	maxLdapMessage     = 64 * 1024        // LDAP单条消息的最大长度
+)
+
+// LocalConfig 本地反连服务配置，监听地址为空时不启动对应服务
+type LocalConfig struct {
+	Domain   string `yaml:"domain" toml:"domain"` // DNS反连使用的根域名，如 oob.example.com
+	IP       string `yaml:"ip" toml:"ip"`         // 对外公布的反连IP，为空时根据监听地址推断
+	DnsAddr  string `yaml:"dns" toml:"dns"`       // DNS监听地址（UDP），如 0.0.0.0:53
+	HttpAddr string `yaml:"http" toml:"http"`     // HTTP监听地址，如 0.0.0.0:8088
+	LdapAddr string `yaml:"ldap" toml:"ldap"`     // LDAP监听地址，如 0.0.0.0:1389
+}
+
+// Hit 一次反连记录
+type Hit struct {
+	Protocol string    // 协议：dns、http、ldap
+	Remote   string    // 来源地址
+	Time     time.Time // 记录时间
+	Data     string    // 请求摘要：DNS查询名、HTTP请求行、LDAP baseDN
+}
+
+// tokenEntry 单个反连标识的记录
+type tokenEntry struct {
+	created time.Time
+	hits    []Hit
+	hit     chan struct{} // 首次命中时关闭
+}
+
+// LocalServer 内置反连服务，提供DNS、HTTP、LDAP监听并在内存中记录命中
+type LocalServer struct {
+	conf       LocalConfig
+	mu         sync.Mutex
+	tokens     map[string]*tokenEntry
+	dnsServer  *dns.Server
+	httpServer *http.Server
+	ldapLn     net.Listener
+	httpPort   string
+	ldapPort   string
+}
+
+// NewLocalServer 创建本地反连服务，需调用 Start 启动监听
+func NewLocalServer(conf LocalConfig) *LocalServer {
+	conf.Domain = strings.Trim(strings.ToLower(conf.Domain), ".")
+	if conf.Domain == "" {
+		conf.Domain = defaultLocalDomain
+	}
+	return &LocalServer{conf: conf, tokens: make(map[string]*tokenEntry)}
+}
+
+// Start 启动已配置的监听，任一监听失败时关闭已启动的服务并返回错误
+func (s *LocalServer) Start() error {
+	if s.conf.DnsAddr == "" && s.conf.HttpAddr == "" && s.conf.LdapAddr == "" {
+		return errors.New("未配置任何反连监听地址")
+	}
+	if s.conf.DnsAddr != "" {
+		pc, err := net.ListenPacket("udp", s.conf.DnsAddr)
+		if err != nil {
+			return fmt.Errorf("DNS反连监听失败: %v", err)
+		}
+		s.dnsServer = &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(s.handleDNS)}
+		go func() { _ = s.dnsServer.ActivateAndServe() }()
+		s.inferIP(pc.LocalAddr())
+		logger.Info(fmt.Sprintf("DNS反连服务已启动：%s，根域名：%s", pc.LocalAddr(), s.conf.Domain))
+	}
+	if s.conf.HttpAddr != "" {
+		ln, err := net.Listen("tcp", s.conf.HttpAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("HTTP反连监听失败: %v", err)
+		}
+		_, s.httpPort, _ = net.SplitHostPort(ln.Addr().String())
+		s.httpServer = &http.Server{Handler: http.HandlerFunc(s.handleHTTP), ReadHeaderTimeout: 10 * time.Second}
+		go func() { _ = s.httpServer.Serve(ln) }()
+		s.inferIP(ln.Addr())
+		logger.Info(fmt.Sprintf("HTTP反连服务已启动：%s", ln.Addr()))
+	}
+	if s.conf.LdapAddr != "" {
+		ln, err := net.Listen("tcp", s.conf.LdapAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("LDAP反连监听失败: %v", err)
+		}
+		_, s.ldapPort, _ = net.SplitHostPort(ln.Addr().String())
+		s.ldapLn = ln
+		go s.serveLdap(ln)
+		s.inferIP(ln.Addr())
+		logger.Info(fmt.Sprintf("LDAP反连服务已启动：%s", ln.Addr()))
+	}
+	if s.conf.IP == "" {
+		s.conf.IP = "127.0.0.1"
+	}
+	return nil
+}
+
+// Close 关闭所有监听
+func (s *LocalServer) Close() {
+	if s == nil {
+		return
+	}
+	if s.dnsServer != nil {
+		_ = s.dnsServer.Shutdown()
+	}
+	if s.httpServer != nil {
+		_ = s.httpServer.Close()
+	}
+	if s.ldapLn != nil {
+		_ = s.ldapLn.Close()
+	}
+}
+
+// inferIP 未配置对外IP时，使用第一个非通配的监听地址
+func (s *LocalServer) inferIP(addr net.Addr) {
+	if s.conf.IP != "" || addr == nil {
+		return
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return
+	}
+	if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+		s.conf.IP = host
+	}
+}
+
+// Domain 返回反连根域名
+func (s *LocalServer) Domain() string {
+	return s.conf.Domain
+}
+
+// IP 返回对外公布的反连IP
+func (s *LocalServer) IP() string {
+	return s.conf.IP
+}
+
+// HasDNS 是否启动了DNS监听
+func (s *LocalServer) HasDNS() bool {
+	return s.dnsServer != nil
+}
+
+// HttpHost 返回HTTP反连地址（ip:port），未启动HTTP监听时返回空
+func (s *LocalServer) HttpHost() string {
+	if s.httpPort == "" {
+		return ""
+	}
+	return net.JoinHostPort(s.conf.IP, s.httpPort)
+}
+
+// LdapHost 返回LDAP反连地址（ip:port），未启动LDAP监听时返回空
+func (s *LocalServer) LdapHost() string {
+	if s == nil || s.ldapPort == "" {
+		return ""
+	}
+	return net.JoinHostPort(s.conf.IP, s.ldapPort)
+}
+
+// NewToken 生成新的反连标识并登记，同时清理过期标识
+func (s *LocalServer) NewToken() string {
+	token := newToken(tokenLength)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for t, e := range s.tokens {
+		if now.Sub(e.created) > tokenTTL {
+			delete(s.tokens, t)
+		}
+	}
+	s.tokens[token] = &tokenEntry{created: now, hit: make(chan struct{})}
+	return token
+}
+
+// record 记录反连命中，未登记的标识忽略
+func (s *LocalServer) record(token string, hit Hit) {
+	token = strings.ToLower(token)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.tokens[token]
+	if !ok {
+		return
+	}
+	if len(e.hits) == 0 {
+		close(e.hit)
+	}
+	e.hits = append(e.hits, hit)
+	logger.Debug(fmt.Sprintf("收到%s反连：%s 来源：%s", hit.Protocol, token, hit.Remote))
+}
+
+// Wait 等待反连标识被命中，最多等待timeout，命中后立即返回true
+func (s *LocalServer) Wait(token string, timeout time.Duration) bool {
+	s.mu.Lock()
+	e, ok := s.tokens[strings.ToLower(token)]
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+	select {
+	case <-e.hit:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Hits 返回反连标识的全部命中记录
+func (s *LocalServer) Hits(token string) []Hit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.tokens[strings.ToLower(token)]
+	if !ok {
+		return nil
+	}
+	return append([]Hit(nil), e.hits...)
+}
+
+// LocalProvider 基于内置反连服务的反连平台，jndi 为true时生成LDAP反连地址
+type LocalProvider struct {
+	server *LocalServer
+	jndi   bool
+}
+
+// Name 返回平台名称
+func (p *LocalProvider) Name() string {
+	return ProviderLocal
+}
+
+// New 生成反连地址：domain 为 标识.根域名，url 优先使用HTTP监听地址；JNDI反连的 url 为LDAP监听地址
+func (p *LocalProvider) New() *proto.Reverse {
+	token := p.server.NewToken()
+	if p.jndi {
+		return buildReverse(fmt.Sprintf("http://%s/%s", p.server.LdapHost(), token), "", p.server.IP(), false)
+	}
+	domain := fmt.Sprintf("%s.%s", token, p.server.Domain())
+	urlStr := fmt.Sprintf("http://%s/%s", domain, token)
+	if host := p.server.HttpHost(); host != "" {
+		urlStr = fmt.Sprintf("http://%s/%s", host, token)
+	}
+	return buildReverse(urlStr, domain, p.server.IP(), p.server.HasDNS())
+}
+
+// Poll 等待内存中的命中记录，命中后立即返回
+func (p *LocalProvider) Poll(r *proto.Reverse, timeout time.Duration) bool {
+	token := pathToken(r, true)
+	if token == "" {
+		token = domainToken(r)
+	}
+	return p.server.Wait(token, timeout)
+}
+
+// Close 本地反连服务由 Close 统一关闭
+func (p *LocalProvider) Close() {}
+
+// tokenFromHost 从 x.token.domain 形式的域名中提取反连标识
+func (s *LocalServer) tokenFromHost(host string) string {
+	host = strings.Trim(strings.ToLower(host), ".")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !strings.HasSuffix(host, "."+s.conf.Domain) {
+		return ""
+	}
+	labels := strings.Split(strings.TrimSuffix(host, "."+s.conf.Domain), ".")
+	return labels[len(labels)-1]
+}
+
+// handleDNS 处理DNS查询，根域名下的A记录统一解析到反连IP
+func (s *LocalServer) handleDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+	for _, q := range r.Question {
+		name := strings.ToLower(q.Name)
+		if token := s.tokenFromHost(name); token != "" {
+			s.record(token, Hit{Protocol: "dns", Remote: w.RemoteAddr().String(), Time: time.Now(), Data: name})
+		}
+		ip := net.ParseIP(s.conf.IP).To4()
+		if q.Qtype == dns.TypeA && ip != nil && (name == s.conf.Domain+"." || strings.HasSuffix(name, "."+s.conf.Domain+".")) {
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 0},
+				A:   ip,
+			})
+		}
+	}
+	_ = w.WriteMsg(m)
+}
+
+// handleHTTP 处理HTTP反连，标识可以位于路径第一段或Host子域名
+func (s *LocalServer) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	hit := Hit{Protocol: "http", Remote: r.RemoteAddr, Time: time.Now(), Data: r.Method + " " + r.RequestURI}
+	if token := s.tokenFromHost(r.Host); token != "" {
+		s.record(token, hit)
+	}
+	if segment := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]; segment != "" {
+		s.record(segment, hit)
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+// serveLdap 处理LDAP连接，JNDI 查询 ldap://ip:port/token 时 baseDN 即为反连标识
+func (s *LocalServer) serveLdap(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleLdap(conn)
+	}
+}
+
+// handleLdap 解析 BindRequest 与 SearchRequest，记录 baseDN 并返回成功结果
+func (s *LocalServer) handleLdap(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	for {
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+		msgID, op, body, err := readLdapMessage(conn)
+		if err != nil {
+			return
+		}
+		switch op {
+		case 0x60: // BindRequest
+			_, _ = conn.Write(ldapResult(msgID, 0x61))
+		case 0x63: // SearchRequest，第一个字段为 baseObject
+			baseDN, _, err := readBerElement(body, 0x04)
+			if err != nil {
+				return
+			}
+			dn := strings.Trim(string(baseDN), "/")
+			token := strings.SplitN(dn, "/", 2)[0]
+			s.record(token, Hit{Protocol: "ldap", Remote: conn.RemoteAddr().String(), Time: time.Now(), Data: dn})
+			_, _ = conn.Write(ldapResult(msgID, 0x65))
+			return
+		default: // UnbindRequest 及其他操作直接断开
+			return
+		}
+	}
+}
+
+// readLdapMessage 读取一条LDAPMessage，返回消息ID、操作类型与操作内容
+func readLdapMessage(conn net.Conn) (int, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := readFull(conn, header); err != nil {
+		return 0, 0, nil, err
+	}
+	if header[0] != 0x30 {
+		return 0, 0, nil, errors.New("invalid ldap message")
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 {
+			return 0, 0, nil, errors.New("invalid ldap length")
+		}
+		lb := make([]byte, n)
+		if _, err := readFull(conn, lb); err != nil {
+			return 0, 0, nil, err
+		}
+		length = 0
+		for _, b := range lb {
+			length = length<<8 | int(b)
+		}
+	}
+	if length < 0 || length > maxLdapMessage {
+		return 0, 0, nil, errors.New("ldap message too large")
+	}
+	data := make([]byte, length)
+	if _, err := readFull(conn, data); err != nil {
+		return 0, 0, nil, err
+	}
+	idBytes, rest, err := readBerElement(data, 0x02)
+	if err != nil || len(rest) == 0 {
+		return 0, 0, nil, errors.New("invalid ldap message id")
+	}
+	msgID := 0
+	for _, b := range idBytes {
+		msgID = msgID<<8 | int(b)
+	}
+	op := rest[0]
+	body, _, err := readBerElement(rest, op)
+	return msgID, op, body, err
+}
+
+// readBerElement 读取指定tag的BER元素，返回内容与剩余数据
+func readBerElement(data []byte, tag byte) ([]byte, []byte, error) {
+	if len(data) < 2 || data[0] != tag {
+		return nil, nil, errors.New("unexpected ber tag")
+	}
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || len(data) < 2+n {
+			return nil, nil, errors.New("invalid ber length")
+		}
+		length = 0
+		for _, b := range data[2 : 2+n] {
+			length = length<<8 | int(b)
+		}
+		offset += n
+	}
+	// 32位平台上4字节长度可能溢出为负数
+	if length < 0 || len(data) < offset+length {
+		return nil, nil, errors.New("truncated ber element")
+	}
+	return data[offset : offset+length], data[offset+length:], nil
+}
+
+// ldapResult 构造 resultCode 为 success 的 LDAPResult 响应
+func ldapResult(msgID int, op byte) []byte {
+	id := []byte{byte(msgID)}
+	for v := msgID >> 8; v > 0; v >>= 8 {
+		id = append([]byte{byte(v)}, id...)
+	}
+	if id[0]&0x80 != 0 {
+		id = append([]byte{0}, id...)
+	}
+	result := []byte{op, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00}
+	msg := append([]byte{0x02, byte(len(id))}, id...)
+	msg = append(msg, result...)
+	return append([]byte{0x30, byte(len(msg))}, msg...)
+}
+
+// readFull 从连接中读取指定长度的数据
+func readFull(conn net.Conn, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := conn.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
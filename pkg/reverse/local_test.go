@@ -0,0 +1,237 @@
+/*
+  - Package reverse
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: local_test.go
+    @Date: 2025/6/26 下午6:10*
+*/
+package reverse
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// ber 构造BER元素，内容不超过127字节时使用短格式长度
+func ber(tag byte, content ...[]byte) []byte {
+	body := bytes.Join(content, nil)
+	if len(body) < 0x80 {
+		return append([]byte{tag, byte(len(body))}, body...)
+	}
+	return append([]byte{tag, 0x82, byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+// ldapMessage 构造 LDAPMessage
+func ldapMessage(msgID byte, op []byte) []byte {
+	return ber(0x30, ber(0x02, []byte{msgID}), op)
+}
+
+// startLocalServer 在本机随机端口启动全部反连监听
+func startLocalServer(t *testing.T) *LocalServer {
+	t.Helper()
+	server := NewLocalServer(LocalConfig{DnsAddr: "127.0.0.1:0", HttpAddr: "127.0.0.1:0", LdapAddr: "127.0.0.1:0"})
+	if err := server.Start(); err != nil {
+		t.Fatalf("启动反连服务失败: %v", err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLocalServerDNS(t *testing.T) {
+	server := startLocalServer(t)
+	provider := &LocalProvider{server: server}
+	r := provider.New()
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn("x."+r.GetDomain()), dns.TypeA)
+	resp, _, err := new(dns.Client).Exchange(msg, server.dnsServer.PacketConn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("DNS查询失败: %v", err)
+	}
+	if len(resp.Answer) != 1 || resp.Answer[0].(*dns.A).A.String() != server.IP() {
+		t.Errorf("DNS应解析到反连IP %s，实际 %v", server.IP(), resp.Answer)
+	}
+	if !provider.Poll(r, 2*time.Second) {
+		t.Fatal("DNS反连未被记录")
+	}
+	if hits := server.Hits(domainToken(r)); len(hits) != 1 || hits[0].Protocol != "dns" {
+		t.Errorf("DNS命中记录不正确: %+v", hits)
+	}
+}
+
+func TestLocalServerHTTP(t *testing.T) {
+	server := startLocalServer(t)
+	provider := &LocalProvider{server: server}
+	r := provider.New()
+
+	resp, err := http.Get(r.GetUrl().GetScheme() + "://" + r.GetUrl().GetHost() + r.GetUrl().GetPath())
+	if err != nil {
+		t.Fatalf("HTTP请求失败: %v", err)
+	}
+	_ = resp.Body.Close()
+	if !provider.Poll(r, 2*time.Second) {
+		t.Fatal("HTTP反连未被记录")
+	}
+	if hits := server.Hits(pathToken(r, true)); len(hits) != 1 || hits[0].Protocol != "http" {
+		t.Errorf("HTTP命中记录不正确: %+v", hits)
+	}
+
+	// 未被访问的标识不应命中
+	if provider.Poll(provider.New(), 100*time.Millisecond) {
+		t.Error("未访问的反连标识不应命中")
+	}
+}
+
+func TestLocalServerLDAP(t *testing.T) {
+	server := startLocalServer(t)
+	provider := &LocalProvider{server: server, jndi: true}
+	r := provider.New()
+	token := pathToken(r, true)
+
+	conn, err := net.DialTimeout("tcp", server.LdapHost(), 2*time.Second)
+	if err != nil {
+		t.Fatalf("连接LDAP失败: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// BindRequest：version 3，匿名绑定
+	bind := ldapMessage(1, ber(0x60, ber(0x02, []byte{3}), ber(0x04), ber(0x80)))
+	if _, err := conn.Write(bind); err != nil {
+		t.Fatalf("发送BindRequest失败: %v", err)
+	}
+	if msgID, op, _, err := readLdapMessage(conn); err != nil || msgID != 1 || op != 0x61 {
+		t.Fatalf("BindResponse不正确：msgID=%d op=%#x err=%v", msgID, op, err)
+	}
+
+	// SearchRequest：baseObject 为反连标识
+	search := ldapMessage(2, ber(0x63, ber(0x04, []byte(token+"/Exploit")), ber(0x0a, []byte{0}), ber(0x0a, []byte{0})))
+	if _, err := conn.Write(search); err != nil {
+		t.Fatalf("发送SearchRequest失败: %v", err)
+	}
+	if msgID, op, _, err := readLdapMessage(conn); err != nil || msgID != 2 || op != 0x65 {
+		t.Fatalf("SearchResultDone不正确：msgID=%d op=%#x err=%v", msgID, op, err)
+	}
+
+	if !provider.Poll(r, 2*time.Second) {
+		t.Fatal("LDAP反连未被记录")
+	}
+	if hits := server.Hits(token); len(hits) != 1 || hits[0].Protocol != "ldap" || hits[0].Data != token+"/Exploit" {
+		t.Errorf("LDAP命中记录不正确: %+v", hits)
+	}
+}
+
+func TestReadBerElementMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"空数据", nil},
+		{"只有tag", []byte{0x04}},
+		{"tag不匹配", []byte{0x02, 0x01, 0x00}},
+		{"长格式长度字节数为0", []byte{0x04, 0x80}},
+		{"长格式长度字节数过多", []byte{0x04, 0x85, 0, 0, 0, 0, 1}},
+		{"长格式长度字节缺失", []byte{0x04, 0x82, 0x01}},
+		{"内容截断", []byte{0x04, 0x05, 'a', 'b'}},
+		{"长格式内容截断", []byte{0x04, 0x82, 0x01, 0x00, 'a'}},
+		{"超大长度", []byte{0x04, 0x84, 0xff, 0xff, 0xff, 0xff, 'a'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := readBerElement(tt.data, 0x04); err == nil {
+				t.Error("无效的BER元素应返回错误")
+			}
+		})
+	}
+
+	content, rest, err := readBerElement([]byte{0x04, 0x81, 0x02, 'o', 'k', 0x01}, 0x04)
+	if err != nil || string(content) != "ok" || !bytes.Equal(rest, []byte{0x01}) {
+		t.Errorf("长格式BER元素解析不正确：content=%q rest=%v err=%v", content, rest, err)
+	}
+}
+
+// readLdapFrom 将数据写入管道后读取一条LDAPMessage
+func readLdapFrom(data []byte) (int, byte, []byte, error) {
+	client, server := net.Pipe()
+	defer func() { _ = server.Close() }()
+	go func() {
+		_, _ = client.Write(data)
+		_ = client.Close()
+	}()
+	_ = server.SetDeadline(time.Now().Add(2 * time.Second))
+	return readLdapMessage(server)
+}
+
+func TestReadLdapMessageMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"空连接", nil},
+		{"非SEQUENCE", []byte{0x31, 0x00}},
+		{"长度字节数为0", []byte{0x30, 0x80}},
+		{"长度字节数过多", []byte{0x30, 0x85, 0, 0, 0, 0, 1}},
+		{"消息过大", []byte{0x30, 0x84, 0x7f, 0xff, 0xff, 0xff}},
+		{"消息截断", []byte{0x30, 0x10, 0x02, 0x01}},
+		{"缺少消息ID", []byte{0x30, 0x02, 0x04, 0x00}},
+		{"缺少操作", []byte{0x30, 0x03, 0x02, 0x01, 0x01}},
+		{"操作内容截断", []byte{0x30, 0x05, 0x02, 0x01, 0x01, 0x60, 0x07}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := readLdapFrom(tt.data); err == nil {
+				t.Error("无效的LDAP消息应返回错误")
+			}
+		})
+	}
+
+	// 完整消息的任意前缀都只返回错误，不会panic
+	valid := ldapMessage(7, ber(0x63, ber(0x04, []byte("token")), ber(0x0a, []byte{0})))
+	for i := 0; i < len(valid); i++ {
+		if _, _, _, err := readLdapFrom(valid[:i]); err == nil {
+			t.Errorf("截断为 %d 字节的LDAP消息应返回错误", i)
+		}
+	}
+	msgID, op, body, err := readLdapFrom(valid)
+	if err != nil || msgID != 7 || op != 0x63 {
+		t.Fatalf("LDAP消息解析不正确：msgID=%d op=%#x err=%v", msgID, op, err)
+	}
+	if baseDN, _, err := readBerElement(body, 0x04); err != nil || string(baseDN) != "token" {
+		t.Errorf("baseObject解析不正确：%q err=%v", baseDN, err)
+	}
+}
+
+func TestHandleLdapMalformed(t *testing.T) {
+	// 畸形输入只断开连接，不记录命中
+	server := NewLocalServer(LocalConfig{})
+	token := server.NewToken()
+	inputs := [][]byte{
+		{0x30, 0x05, 0x02, 0x01, 0x01, 0x63, 0x00},               // SearchRequest 缺少 baseObject
+		{0x30, 0x07, 0x02, 0x01, 0x01, 0x63, 0x02, 0x04, 0x05},   // baseObject 截断
+		ldapMessage(1, ber(0x42)),                                // UnbindRequest
+		append([]byte{0x30, 0x84, 0x00, 0x00, 0x00, 0x10}, 0x02), // 消息截断
+	}
+	for _, data := range inputs {
+		client, conn := net.Pipe()
+		done := make(chan struct{})
+		go func() {
+			server.handleLdap(conn)
+			close(done)
+		}()
+		_, _ = client.Write(data)
+		_ = client.Close()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("畸形输入 %x 未断开连接", data)
+		}
+	}
+	if hits := server.Hits(token); len(hits) != 0 {
+		t.Errorf("畸形输入不应记录命中: %+v", hits)
+	}
+}
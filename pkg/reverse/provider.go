@@ -0,0 +1,171 @@
+/*
+  - Package reverse
+    @Author: zhizhuo
+    @IDE：GoLand
+    @File: provider.go
+    @Date: 2025/6/15 上午10:05*
+*/
+package reverse
+
+import (
+	"fmt"
+	"gxx/utils/common"
+	"gxx/utils/logger"
+	"gxx/utils/proto"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider 反连平台接口，New 生成反连地址，Poll 检查反连地址在timeout内是否被访问
+type Provider interface {
+	Name() string
+	New() *proto.Reverse
+	Poll(r *proto.Reverse, timeout time.Duration) bool
+	Close()
+}
+
+var (
+	current     Provider = &CeyeProvider{} // DNS/HTTP反连平台，默认ceye
+	jndiCurrent Provider = &HttpProvider{} // JNDI反连平台，默认未配置的自定义HTTP API
+	providerMu  sync.RWMutex
+	localServer *LocalServer // 本地反连服务，未使用时为空
+)
+
+// Init 根据配置初始化反连平台，使用本地反连服务时启动监听；重复调用时关闭旧的平台
+func Init(conf *Config) error {
+	if conf == nil {
+		conf = &Config{}
+	}
+	var server *LocalServer
+	if conf.Provider == ProviderLocal || conf.jndiProvider() == ProviderLocal {
+		server = NewLocalServer(conf.Local)
+		if err := server.Start(); err != nil {
+			return err
+		}
+	}
+	provider, err := newProvider(conf.Provider, conf, server, false)
+	if err != nil {
+		server.Close()
+		return err
+	}
+	jndi, err := newProvider(conf.jndiProvider(), conf, server, true)
+	if err != nil {
+		server.Close()
+		return err
+	}
+	Close()
+	providerMu.Lock()
+	current, jndiCurrent, localServer = provider, jndi, server
+	providerMu.Unlock()
+	logger.Debug(fmt.Sprintf("反连平台：%s，JNDI反连平台：%s", provider.Name(), jndi.Name()))
+	return nil
+}
+
+// newProvider 根据名称创建反连平台
+func newProvider(name string, conf *Config, server *LocalServer, jndi bool) (Provider, error) {
+	switch name {
+	case "", ProviderCeye:
+		if jndi {
+			return nil, fmt.Errorf("反连平台 %s 不支持JNDI反连", ProviderCeye)
+		}
+		return &CeyeProvider{conf: conf.Ceye}, nil
+	case ProviderInteractsh:
+		if jndi {
+			return nil, fmt.Errorf("反连平台 %s 不支持JNDI反连", ProviderInteractsh)
+		}
+		return NewInteractshProvider(conf.Interactsh)
+	case ProviderHttp:
+		if jndi {
+			return &HttpProvider{conf: conf.Jndi}, nil
+		}
+		return &HttpProvider{conf: conf.Http}, nil
+	case ProviderLocal:
+		if jndi && server.LdapHost() == "" {
+			return nil, fmt.Errorf("本地反连服务未配置LDAP监听地址，无法用于JNDI反连")
+		}
+		return &LocalProvider{server: server, jndi: jndi}, nil
+	default:
+		return nil, fmt.Errorf("未知的反连平台: %s", name)
+	}
+}
+
+// Close 关闭当前反连平台并恢复默认配置
+func Close() {
+	providerMu.Lock()
+	provider, jndi, server := current, jndiCurrent, localServer
+	current, jndiCurrent, localServer = &CeyeProvider{}, &HttpProvider{}, nil
+	providerMu.Unlock()
+	provider.Close()
+	jndi.Close()
+	server.Close()
+}
+
+// Current 返回DNS/HTTP反连平台
+func Current() Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return current
+}
+
+// JNDI 返回JNDI反连平台
+func JNDI() Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return jndiCurrent
+}
+
+// newToken 生成小写的反连标识
+func newToken(length int) string {
+	return strings.ToLower(common.RandomString(length))
+}
+
+// buildReverse 根据反连地址构造 proto.Reverse
+func buildReverse(urlStr, domain, ip string, nameServer bool) *proto.Reverse {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		u = &url.URL{}
+	}
+	if domain == "" {
+		domain = u.Hostname()
+	}
+	return &proto.Reverse{
+		Url:                common.ParseUrl(u),
+		Domain:             domain,
+		Ip:                 ip,
+		IsDomainNameServer: nameServer,
+	}
+}
+
+// domainToken 返回域名第一段作为反连标识
+func domainToken(r *proto.Reverse) string {
+	return strings.ToLower(strings.Split(r.GetDomain(), ".")[0])
+}
+
+// pathToken 返回url路径中的反连标识，first 为true时取第一段，否则取最后一段
+func pathToken(r *proto.Reverse, first bool) string {
+	segments := strings.Split(strings.Trim(r.GetUrl().GetPath(), "/"), "/")
+	if first {
+		return segments[0]
+	}
+	return segments[len(segments)-1]
+}
+
+// pollUntil 每隔interval执行一次检查，直到检查成功或超过timeout，超时前至少检查一次
+func pollUntil(timeout, interval time.Duration, check func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if check() {
+			return true
+		}
+		remain := time.Until(deadline)
+		if remain <= 0 {
+			return false
+		}
+		if remain < interval {
+			interval = remain
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+const (
+	DefaultDedupSize = 4000000 // 目标去重的默认预计目标数量
+	dedupFPRate      = 1e-6    // 目标去重的误判率，误判时会跳过未扫描过的目标
+)
+
+// bloomFilter 布隆过滤器，用固定大小的内存对大量目标去重，不保存目标本身
+type bloomFilter struct {
+	bits  []uint64
+	m     uint64 // 位数
+	k     uint64 // 哈希函数个数
+	seed1 maphash.Seed
+	seed2 maphash.Seed
+}
+
+// newBloomFilter 按预计元素数量与误判率创建布隆过滤器
+func newBloomFilter(capacity int, fpRate float64) *bloomFilter {
+	if capacity < 1 {
+		capacity = 1
+	}
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(capacity) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{
+		bits:  make([]uint64, (m+63)/64),
+		m:     m,
+		k:     k,
+		seed1: maphash.MakeSeed(),
+		seed2: maphash.MakeSeed(),
+	}
+}
+
+// add 添加元素，元素可能已存在时返回 true
+func (b *bloomFilter) add(s string) bool {
+	h1 := maphash.String(b.seed1, s)
+	h2 := maphash.String(b.seed2, s) | 1
+	exists := true
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if b.bits[word]&mask == 0 {
+			exists = false
+			b.bits[word] |= mask
+		}
+	}
+	return exists
+}
+
+// reset 清空过滤器
+func (b *bloomFilter) reset() {
+	clear(b.bits)
+}
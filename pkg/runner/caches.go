@@ -8,8 +8,10 @@
 package runner
 
 import (
+	"bytes"
 	"fmt"
 	"gxx/pkg/finger"
+	"gxx/pkg/network"
 	"gxx/utils/common"
 	"gxx/utils/logger"
 	"gxx/utils/proto"
@@ -17,6 +19,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	googleproto "google.golang.org/protobuf/proto"
 )
 
 // CacheRequest 存储请求和响应的缓存条目
@@ -119,6 +123,14 @@ func GenerateCacheKey(target string, method string, followRedirects bool) string
 	return common.MD5Hash(target + ":" + method + ":" + strconv.FormatBool(followRedirects))
 }
 
+// vhostCacheTarget 返回虚拟主机目标的缓存目标，同一地址的不同虚拟主机使用不同的缓存
+func vhostCacheTarget(target, vhost string) string {
+	if vhost == "" || target == "" {
+		return target
+	}
+	return vhost + common.VhostSeparator + target
+}
+
 // ShouldUseCache 判断是否应该使用缓存，对于根路径的GET请求，可以重用缓存的请求和响应
 func ShouldUseCache(rule finger.RuleMap, target string) (bool, CacheRequest) {
 	var caches CacheRequest
@@ -156,6 +168,10 @@ func ShouldUseCache(rule finger.RuleMap, target string) (bool, CacheRequest) {
 	if exists && entry != nil && entry.Request != nil && entry.Response != nil {
 		// 检查缓存是否过期
 		if time.Since(time.Unix(entry.Timestamp, 0)) <= globalCacheManager.ttl {
+			// 缓存的响应体已被截断，而规则需要读取更多内容时重新请求
+			if entry.Response.GetTruncated() && int64(rule.Value.Request.MaxBody) > int64(len(entry.Response.GetBody())) {
+				return false, caches
+			}
 			caches.Request = entry.Request
 			caches.Response = entry.Response
 			return true, caches
@@ -209,23 +225,8 @@ func UpdateTargetCache(variableMap map[string]any, target string, followRedirect
 
 	logger.Debug(fmt.Sprintf("请求缓存key：%s %s %s %t", cacheKey, urlStr, method, followRedirects))
 
-	// 创建缓存条目（对大字段进行截断，避免缓存过大）
-	const maxCacheSize = 1 << 20 // 1MB
-	if len(resp.Body) > maxCacheSize {
-		resp.Body = resp.Body[:maxCacheSize]
-	}
-	if len(resp.Raw) > maxCacheSize {
-		resp.Raw = resp.Raw[:maxCacheSize]
-	}
-	if len(resp.RawHeader) > maxCacheSize {
-		resp.RawHeader = resp.RawHeader[:maxCacheSize]
-	}
-	if len(req.Raw) > maxCacheSize {
-		req.Raw = req.Raw[:maxCacheSize]
-	}
-	if len(req.Body) > maxCacheSize {
-		req.Body = req.Body[:maxCacheSize]
-	}
+	// 创建缓存条目，超过读取上限的响应体（规则设置了更大的 max-body）截断后缓存，不修改当前规则使用的响应
+	resp = capCachedResponse(resp, network.MaxDefaultBody)
 
 	cacheEntry := &CacheRequest{
 		Request:   req,
@@ -244,6 +245,25 @@ func UpdateTargetCache(variableMap map[string]any, target string, followRedirect
 	globalCacheManager.cache[cacheKey] = cacheEntry
 }
 
+// capCachedResponse 返回响应体不超过 limit 字节的响应，未超过时返回原响应，超过时返回截断后的副本并标记 Truncated；
+// 缓存命中时规则的 max-body 大于截断后的长度会重新请求，见 ShouldUseCache
+func capCachedResponse(resp *proto.Response, limit int64) *proto.Response {
+	if limit <= 0 || int64(len(resp.Body)) <= limit {
+		return resp
+	}
+	capped := googleproto.Clone(resp).(*proto.Response)
+	cut := int64(len(resp.Body)) - limit
+	// raw 以响应体结尾，去掉相同长度的尾部
+	if bytes.HasSuffix(capped.Raw, capped.Body) {
+		capped.Raw = capped.Raw[:int64(len(capped.Raw))-cut]
+	}
+	capped.Body = capped.Body[:limit]
+	capped.Text, _ = network.DecodeText(capped.Body, capped.ContentType)
+	capped.Truncated = true
+	logger.Debug(fmt.Sprintf("缓存的响应体超过读取上限 %d 字节，已截断", limit))
+	return capped
+}
+
 // ClearTargetURLCache 删除与特定URL相关的所有缓存，无论请求方法和跟随重定向设置如何
 func ClearTargetURLCache(target string) {
 	if target == "" {
@@ -288,18 +308,39 @@ func ClearAllCache() {
 	// 重新初始化缓存映射
 	globalCacheManager.cache = make(map[string]*CacheRequest, 2048)
 	globalCacheManager.mutex.Unlock()
+	network.ClearSchemeCache()
 	logger.Debug("已清空所有缓存")
 }
 
+// SetCacheOptions 设置缓存最大条目数与有效期，参数为0时保持原值
+//
+//	@param maxSize 最大缓存条目数
+//	@param ttl 缓存有效期
+func SetCacheOptions(maxSize int, ttl time.Duration) {
+	globalCacheManager.mutex.Lock()
+	defer globalCacheManager.mutex.Unlock()
+	if maxSize > 0 {
+		globalCacheManager.maxSize = maxSize
+	}
+	if ttl > 0 {
+		globalCacheManager.ttl = ttl
+	}
+	logger.Debug(fmt.Sprintf("缓存配置已更新: 最大条目数=%d, 有效期=%v", globalCacheManager.maxSize, globalCacheManager.ttl))
+}
+
 // GetCacheStats 获取缓存统计信息
 func GetCacheStats() map[string]interface{} {
+	scheme := network.GetSchemeCacheStats()
 	globalCacheManager.mutex.RLock()
 	defer globalCacheManager.mutex.RUnlock()
 
 	return map[string]interface{}{
-		"total_entries": len(globalCacheManager.cache),
-		"max_size":      globalCacheManager.maxSize,
-		"ttl_minutes":   globalCacheManager.ttl.Minutes(),
-		"last_cleanup":  globalCacheManager.lastCleanup.Format(time.RFC3339),
+		"total_entries":  len(globalCacheManager.cache),
+		"max_size":       globalCacheManager.maxSize,
+		"ttl_minutes":    globalCacheManager.ttl.Minutes(),
+		"last_cleanup":   globalCacheManager.lastCleanup.Format(time.RFC3339),
+		"scheme_hits":    scheme.Hits,
+		"scheme_misses":  scheme.Misses,
+		"scheme_entries": scheme.Entries,
 	}
 }
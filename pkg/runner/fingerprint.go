@@ -33,7 +33,8 @@ func GetAllFingerSnapshot() []*finger.Finger {
 	return snapshot
 }
 
-// LoadFingerprints 加载指纹规则文件，支持从默认嵌入指纹库、指定目录或单个YAML文件加载
+// LoadFingerprints 加载指纹规则文件，支持从默认嵌入指纹库、指定目录或单个YAML文件加载，
+// 指定 nmap-service-probes 文件时将其中的服务探针转换为指纹一并加载
 func LoadFingerprints(options types.YamlFingerType) error {
 	allFingerMutex.Lock()
 	defer allFingerMutex.Unlock()
@@ -41,6 +42,26 @@ func LoadFingerprints(options types.YamlFingerType) error {
 	// 清空现有指纹规则
 	AllFinger = AllFinger[:0]
 
+	if err := loadYamlFingerprints(options); err != nil {
+		return err
+	}
+
+	// 导入nmap服务探针
+	if options.NmapProbes != "" {
+		fingers, err := finger.ReadNmapProbes(options.NmapProbes, options.NmapIntensity)
+		if err != nil {
+			return fmt.Errorf("读取nmap服务探针出错: %v", err)
+		}
+		logger.Info(fmt.Sprintf("加载nmap服务探针：%s，探测强度：%d，转换指纹数量：%v个", options.NmapProbes, options.NmapIntensity, len(fingers)))
+		AllFinger = append(AllFinger, fingers...)
+	}
+
+	return nil
+}
+
+// loadYamlFingerprints 加载yaml指纹规则，调用方需持有写锁
+func loadYamlFingerprints(options types.YamlFingerType) error {
+
 	// 使用嵌入式指纹库
 	if options.PocFile == "" && options.PocYaml == "" {
 		logger.Info("使用默认指纹库")
@@ -97,8 +118,9 @@ func GetFingerCount() int {
 	return len(AllFinger)
 }
 
-// evaluateFingerprintWithCache 使用缓存的基础信息评估指纹规则，执行单个指纹的识别逻辑，包括发送请求和规则评估
-func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *BaseInfo, proxy string, timeout int) (*FingerMatch, error) {
+// evaluateFingerprintWithCache 使用缓存的基础信息评估指纹规则，执行单个指纹的识别逻辑，包括发送请求和规则评估，
+// vhost 不为空时所有规则请求使用虚拟主机作为 Host 与 SNI
+func evaluateFingerprintWithCache(fg *finger.Finger, target, vhost string, baseInfo *BaseInfo, proxy string, timeout int) (*FingerMatch, error) {
 	customLib := cel2.NewCustomLib()
 
 	// 初始化变量映射
@@ -113,19 +135,29 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 	// 设置基础变量容器（请求/响应会在缓存命中或首次请求后赋值）
 	varMap["title"] = baseInfo.Title
 	varMap["server"] = baseInfo.Server
+	varMap["affected"] = fg.Info.Affected
 
 	// 初始化响应对象
 	varMap["response"] = &proto.Response{
-		Status:      baseInfo.StatusCode,
-		Headers:     map[string]string{},
-		ContentType: "",
-		Body:        []byte{},
-		Raw:         []byte{},
-		RawHeader:   []byte{},
-		Url:         &proto.UrlType{},
-		Latency:     0,
+		Status:       baseInfo.StatusCode,
+		Headers:      map[string]string{},
+		ContentType:  "",
+		Body:         []byte{},
+		Raw:          []byte{},
+		RawHeader:    []byte{},
+		Url:          &proto.UrlType{},
+		Latency:      0,
+		Cookies:      map[string]string{},
+		HeaderValues: map[string]*proto.HeaderValues{},
 	}
 
+	// 初始化会话，规则之间共享cookie jar（session: false 时关闭）
+	var session *finger.Session
+	if fg.UseSession() {
+		session = finger.NewSession()
+	}
+	varMap["session"] = session.ToProto(target)
+
 	// 处理预设规则
 	if len(fg.Set) > 0 {
 		finger.IsFuzzSet(fg.Set, varMap, customLib)
@@ -136,20 +168,34 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 
 	// 评估规则
 	for _, rule := range fg.Rules {
+		// 规则请求引用会话变量时请求内容依赖会话状态，需在替换变量前判断
+		usesSession := session != nil && ruleUsesSession(rule.Value.Request)
+
 		// 提前处理path
 		rule.Value.Request.Path = finger.SetVariableMap(strings.TrimSpace(rule.Value.Request.Path), varMap)
 		urlStr := common.ParseTarget(target, rule.Value.Request.Path)
 
+		// 会话中有前序规则实际请求获得的cookie或规则请求引用会话变量时请求结果依赖会话状态，
+		// 指纹单独配置TLS客户端指纹时响应也可能不同，均不使用也不更新共享缓存；
+		// 仅由缓存响应写入的cookie（如共享的首页响应）与缓存请求一致，不影响缓存使用
+		isolated := session.HasRequestCookies(urlStr) || usesSession || fg.TLSFingerprint != ""
+
 		// 检查是否可以使用缓存
-		isCache, cache := ShouldUseCache(rule, urlStr)
+		isCache, cache := ShouldUseCache(rule, vhostCacheTarget(urlStr, vhost))
+		isCache = isCache && !isolated
 		logger.Debug(fmt.Sprintf("%s 规则 %s 是否使用缓存：%t", target, rule.Key, isCache))
 
 		if isCache && cache.Request != nil && cache.Response != nil {
 			varMap["request"] = cache.Request
 			varMap["response"] = cache.Response
+			// 缓存响应的 Set-Cookie 同样写入会话，后续规则（如登录后访问接口）携带该cookie
+			if session != nil {
+				session.AddResponseCookies(urlStr, cache.Response)
+				varMap["session"] = session.ToProto(urlStr)
+			}
 		} else {
 			// 发送新请求
-			newVarMap, err := finger.SendRequest(target, rule.Value.Request, rule.Value, varMap, proxy, timeout)
+			newVarMap, err := finger.SendRequest(target, rule.Value.Request, rule.Value, varMap, proxy, timeout, session, fg.TLSFingerprint, vhost, baseInfo.Errors)
 			if err != nil {
 				logger.Debug(fmt.Sprintf("规则 %s 请求失败: %v", rule.Key, err))
 				customLib.WriteRuleFunctionsROptions(rule.Key, false)
@@ -159,9 +205,9 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 			// 更新变量映射
 			if len(newVarMap) > 0 {
 				varMap = newVarMap
-				// 只有头部和body为空的请求才缓存
-				if len(rule.Value.Request.Headers) == 0 {
-					UpdateTargetCache(varMap, urlStr, rule.Value.Request.FollowRedirects)
+				// 只有头部和body为空、未携带会话cookie且使用全局TLS客户端指纹的请求才缓存
+				if len(rule.Value.Request.Headers) == 0 && !isolated {
+					UpdateTargetCache(varMap, vhostCacheTarget(urlStr, vhost), rule.Value.Request.FollowRedirects)
 				}
 			}
 		}
@@ -217,9 +263,37 @@ func evaluateFingerprintWithCache(fg *finger.Finger, target string, baseInfo *Ba
 		if resp, ok := varMap["response"].(*proto.Response); ok {
 			resultData.Response = resp
 		}
+		resultData.Extracted = collectOutputs(fg, varMap)
 	}
 
 	logger.Debug(fmt.Sprintf("最终规则 %s 评估结果: %v", fg.Expression, resultData.Result))
 
 	return resultData, nil
 }
+
+// collectOutputs 收集规则 output 中提取到的字符串字段，如 nmap 服务探针的 product、version、cpe；
+// 求值失败时变量保留表达式原文，不作为提取结果
+func collectOutputs(fg *finger.Finger, varMap map[string]any) map[string]string {
+	var extracted map[string]string
+	for _, rule := range fg.Rules {
+		for _, item := range rule.Value.Output {
+			key, _ := item.Key.(string)
+			expr, _ := item.Value.(string)
+			value, ok := varMap[key].(string)
+			if !ok || value == "" || value == expr {
+				continue
+			}
+			if extracted == nil {
+				extracted = make(map[string]string)
+			}
+			extracted[key] = value
+		}
+	}
+	return extracted
+}
+
+// ruleUsesSession 判断规则请求是否引用会话变量（如 path 中的 {{session}}），
+// 携带自定义头部或请求体的规则本身不使用缓存，这里只需检查 path 与 raw
+func ruleUsesSession(req finger.RuleRequest) bool {
+	return strings.Contains(req.Path, "{{session") || strings.Contains(req.Raw, "{{session")
+}
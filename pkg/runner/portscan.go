@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"fmt"
+	"gxx/pkg/finger"
+	"gxx/pkg/network"
+	"gxx/types"
+	"gxx/utils/common"
+	"gxx/utils/logger"
+	"strings"
+	"time"
+)
+
+// ProcessPort 对 "host:port" 目标执行TCP连接扫描与协议识别：HTTP(S)端口交由 ProcessURL 识别，
+// TLS与其他TCP服务只执行 tcp/udp 指纹，服务器信息来自识别的协议与指纹提取的产品版本；
+// URL目标直接交由 ProcessURL 处理，端口未开放时返回 nil
+func ProcessPort(target string, proxy string, timeout int, workers int) (*TargetResult, error) {
+	addr, vhost := common.SplitVhost(target)
+	if strings.Contains(addr, "://") {
+		return ProcessURL(target, proxy, timeout, workers)
+	}
+
+	d := time.Duration(timeout) * time.Second
+	if !network.ScanPort(addr, proxy, d) {
+		logger.Debug(fmt.Sprintf("端口 %s 未开放", addr))
+		return nil, nil
+	}
+	service := network.DetectService(addr, vhost, proxy, d)
+	logger.Debug(fmt.Sprintf("端口 %s 开放，识别协议：%s", addr, service))
+
+	if service == network.ServiceHTTP || service == network.ServiceHTTPS {
+		return ProcessURL(common.JoinVhost(service+"://"+addr, vhost), proxy, timeout, workers)
+	}
+
+	errs := network.NewErrorStats()
+	targetResult := &TargetResult{
+		URL:     addr,
+		VHost:   vhost,
+		Server:  finger.GetServerInfoFromTCP(addr, service),
+		Matches: make([]*FingerMatch, 0),
+	}
+	// TLS服务以 https:// 形式传入，tcp 规则未指定 host 时通过TLS连接（如 993、995、465 端口）
+	fingerTarget := addr
+	if service == network.ServiceTLS {
+		fingerTarget = "https://" + addr
+	}
+	if len(AllFinger) > 0 {
+		targetResult.Matches = runFingerDetection(fingerTarget, vhost, &BaseInfo{Server: targetResult.Server, Errors: errs}, proxy, timeout, isServiceFinger)
+	}
+	applyExtractedServer(targetResult.Server, targetResult.Matches)
+	targetResult.setErrors(errs)
+	return targetResult, nil
+}
+
+// applyExtractedServer 指纹从服务响应中提取到产品名称时，使用其产品与版本作为服务器类型与版本号
+func applyExtractedServer(server *types.ServerInfo, matches []*FingerMatch) {
+	for _, match := range matches {
+		product := match.Extracted["product"]
+		if product == "" {
+			continue
+		}
+		server.ServerType = product
+		if version := match.Extracted["version"]; version != "" {
+			server.Version = version
+		}
+		return
+	}
+}
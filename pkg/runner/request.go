@@ -37,16 +37,17 @@ func initializeCache(base *BaseInfoResponse, proxy string) (*proto.Response, *pr
 	// 重置响应体（供后续使用）
 	httpResp.Body = io.NopCloser(bytes.NewReader(respBody))
 
-	utf8RespBody := common.Str2UTF8(string(respBody))
-
 	// 构建响应/请求对象
-	initialResponse := finger.BuildProtoResponse(httpResp, utf8RespBody, 0, proxy)
+	initialResponse := finger.BuildProtoResponse(httpResp, respBody, base.Timing, base.ConnInfo, proxy, base.VHost)
+	initialResponse.Truncated = base.Truncated
 	initialRequest := finger.BuildProtoRequest(httpResp, "GET", "", "/")
 	return initialResponse, initialRequest
 }
 
 // GetBaseInfo 获取目标的基础信息并返回 BaseInfoResponse 结构体
+// target 可携带虚拟主机，如 "https://10.0.0.5:8443 | oa.corp.local"，此时请求使用虚拟主机作为 Host 与 SNI
 func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
+	target, vhost := common.SplitVhost(target)
 	// 检查并规范化URL协议
 	if checkedURL, err := network.CheckProtocol(target, proxy); err == nil && checkedURL != "" {
 		target = checkedURL
@@ -66,31 +67,56 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 	options := network.OptionsRequest{
 		Proxy:              proxy,
 		Timeout:            timeoutDuration,
-		Retries:            3,
 		FollowRedirects:    true,
 		InsecureSkipVerify: true,
+		Timing:             network.NewTiming(),
+		ConnInfo:           network.NewConnInfo(),
+		VHost:              vhost,
+		Errors:             network.NewErrorStats(),
 	}
 
-	// 发送请求
-	ctx, cancel := context.WithTimeout(context.Background(), timeoutDuration)
+	// 发送请求，等待主机并发槽位后再开始计时，超时时间包含全部重试
+	release := network.AcquireHostSlot(target)
+	defer release()
+	options.Timing.Restart()
+	ctx, cancel := context.WithTimeout(context.Background(), network.Retry.Budget(timeoutDuration))
 	defer cancel()
 
 	resp, err := network.SendRequestHttp(ctx, "GET", target, "", options)
 	if err != nil {
 		return &BaseInfoResponse{
 			Url:        target,
+			VHost:      vhost,
 			Title:      "",
 			Server:     types.EmptyServerInfo(),
 			StatusCode: 0,
 			Response:   resp,
 			Wappalyzer: nil,
 			BodyBytes:  nil,
+			Errors:     options.Errors,
 		}, fmt.Errorf("发送请求失败: %v", err)
 	}
 
+	// 记录主机实际使用的协议，规则请求中同一主机的无协议URL不再重复探测
+	network.RememberScheme(target)
+
+	// 读取并解压响应体一次并保存，后续复用（限制大小，避免大包体导致内存暴涨），读取完毕时结束计时
+	resp.Body = options.Timing.WrapBody(resp.Body)
+	data, truncated, err := network.ReadBody(resp, network.MaxDefaultBody)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("读取响应体出错: %v", err))
+		data = []byte{}
+	}
+	_ = resp.Body.Close()
+	options.Timing.Done()
+	// 读取完响应体后释放并发槽位，提取标题时可能请求同一主机的i18n文件
+	release()
+	// 重置响应体以供后续使用
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
 	// 提取基本信息
 	statusCode := int32(resp.StatusCode)
-	title := finger.GetTitle(target, resp)
+	title := finger.GetTitle(target, resp, proxy)
 	serverInfo := finger.GetServerInfoFromResponse(resp)
 	newURL, _ := url.Parse(target)
 	if resp.Request != nil {
@@ -103,32 +129,35 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 		// 即使获取站点技术信息失败，仍然返回基本信息
 		return &BaseInfoResponse{
 			Url:        target,
+			VHost:      vhost,
 			Title:      title,
 			Server:     serverInfo,
 			StatusCode: statusCode,
 			Response:   resp,
 			Wappalyzer: nil,
+			BodyBytes:  data,
+			Truncated:  truncated,
+			Timing:     options.Timing,
+			ConnInfo:   options.ConnInfo,
+			Errors:     options.Errors,
 		}, nil
 	}
-	// 读取响应体一次并保存，后续复用（限制大小，避免大包体导致内存暴涨）
-	data, err := io.ReadAll(io.LimitReader(resp.Body, network.MaxDefaultBody))
-	if err != nil {
-		logger.Debug(fmt.Sprintf("读取响应体出错: %v", err))
-		data = []byte{}
-	}
-	// 重置响应体以供后续使用
-	resp.Body = io.NopCloser(bytes.NewReader(data))
-
 	wappData, err := wapp.GetWappalyzer(resp.Header, data)
 	if err != nil {
 		// 即使获取Wappalyzer数据失败，仍然返回基本信息
 		return &BaseInfoResponse{
 			Url:        target,
+			VHost:      vhost,
 			Title:      title,
 			Server:     serverInfo,
 			StatusCode: statusCode,
 			Response:   resp,
 			Wappalyzer: nil,
+			BodyBytes:  data,
+			Truncated:  truncated,
+			Timing:     options.Timing,
+			ConnInfo:   options.ConnInfo,
+			Errors:     options.Errors,
 		}, nil
 	}
 
@@ -136,11 +165,16 @@ func GetBaseInfo(target, proxy string, timeout int) (*BaseInfoResponse, error) {
 
 	return &BaseInfoResponse{
 		Url:        target,
+		VHost:      vhost,
 		Title:      title,
 		Server:     serverInfo,
 		StatusCode: statusCode,
 		Response:   resp,
 		Wappalyzer: wappData,
 		BodyBytes:  data,
+		Truncated:  truncated,
+		Timing:     options.Timing,
+		ConnInfo:   options.ConnInfo,
+		Errors:     options.Errors,
 	}, nil
 }
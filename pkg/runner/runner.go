@@ -2,7 +2,10 @@ package runner
 
 import (
 	"fmt"
+	"gxx/pkg/network"
+	"gxx/pkg/reverse"
 	"gxx/types"
+	"gxx/utils/common"
 	"gxx/utils/logger"
 	"gxx/utils/output"
 	"sync"
@@ -20,10 +23,9 @@ const (
 
 // Runner 指纹识别运行器
 type Runner struct {
-	Config    *ScanConfig              // 配置参数
-	Results   map[string]*TargetResult // 扫描结果
-	mutex     sync.RWMutex             // 读写锁保护Results
-	isRunning atomic.Bool              // 运行状态标志
+	Config    *ScanConfig // 配置参数
+	Stats     *ScanStats  // 扫描统计，结果直接写入输出，不在内存中保存
+	isRunning atomic.Bool // 运行状态标志
 }
 
 // NewRunner 创建一个新的扫描运行器
@@ -64,9 +66,8 @@ func NewRunner(options *types.CmdOptions) *Runner {
 
 	// 创建Runner实例
 	runner := &Runner{
-		Config:  config,
-		Results: make(map[string]*TargetResult),
-		mutex:   sync.RWMutex{},
+		Config: config,
+		Stats:  &ScanStats{},
 	}
 
 	return runner
@@ -79,13 +80,22 @@ func (r *Runner) Run(options *types.CmdOptions) error {
 	}
 	defer r.isRunning.Store(false)
 
-	// 处理目标URL列表
-	targets := getTargets(options)
-	if len(targets) == 0 {
+	// 处理目标URL列表，CIDR、IP段与端口范围在扫描时才展开
+	targets, err := getTargets(options)
+	if err != nil {
+		return err
+	}
+	total, err := targets.count()
+	if err != nil {
+		return err
+	}
+	if total == 0 {
 		return fmt.Errorf("未找到有效的目标URL")
 	}
 
-	logger.Info(fmt.Sprintf("准备扫描 %d 个目标", len(targets)))
+	if total > 0 {
+		logger.Info(fmt.Sprintf("准备扫描 %d 个目标", total))
+	}
 
 	// 初始化输出文件
 	if r.Config.OutputFile != "" {
@@ -111,6 +121,19 @@ func (r *Runner) Run(options *types.CmdOptions) error {
 	}
 	logger.Info(fmt.Sprintf("加载指纹数量：%v个", len(AllFinger)))
 
+	// 应用配置文件中的HTTP、缓存与内存设置
+	if err := applySettings(options); err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+
+	// 初始化反连平台
+	if err := initReverse(options); err != nil {
+		logger.Error(fmt.Sprintf("初始化反连平台失败: %v", err))
+		return fmt.Errorf("初始化反连平台失败: %v", err)
+	}
+	defer reverse.Close()
+
 	// 初始化全局规则池
 	if !IsRulePoolInitialized() {
 		if err := InitGlobalRulePool(r.Config.FingerWorkerCount); err != nil {
@@ -121,11 +144,11 @@ func (r *Runner) Run(options *types.CmdOptions) error {
 	// 在函数返回时释放全局池资源
 	defer ReleaseRulePool()
 
-	logger.Info(fmt.Sprintf("开始扫描 %d 个目标，使用 %d 个URL并发线程, %d 个规则并发线程...",
-		len(targets), r.Config.URLWorkerCount, r.Config.FingerWorkerCount))
+	logger.Info(fmt.Sprintf("开始扫描，使用 %d 个URL并发线程, %d 个规则并发线程...",
+		r.Config.URLWorkerCount, r.Config.FingerWorkerCount))
 
 	// 执行扫描
-	if err := r.runScan(targets, options); err != nil {
+	if err := r.runScan(targets, total, options); err != nil {
 		return err
 	}
 
@@ -133,9 +156,7 @@ func (r *Runner) Run(options *types.CmdOptions) error {
 	ClearAllCache()
 
 	// 打印统计信息
-	r.mutex.RLock()
-	printSummary(targets, r.Results)
-	r.mutex.RUnlock()
+	printSummary(r.Stats)
 
 	return nil
 }
@@ -155,37 +176,11 @@ func (r *Runner) ScanTarget(target string) (*TargetResult, error) {
 	return result, nil
 }
 
-// runScan 执行扫描过程
-func (r *Runner) runScan(targets []string, options *types.CmdOptions) error {
-	// 使用较小缓冲通道收集结果，避免为大规模目标一次性分配巨大缓冲区
-	resultChan := make(chan struct {
-		target string
-		result *TargetResult
-	}, func() int {
-		caps := r.Config.URLWorkerCount * 2
-		if caps < 1 {
-			caps = 1
-		}
-		if caps > len(targets) {
-			return len(targets)
-		}
-		return caps
-	}())
-
+// runScan 执行扫描过程，total 为目标总数（未知时为-1）；目标逐个生成并提交到线程池，线程全忙时阻塞等待，
+// 结果直接写入输出并累计统计，不丢弃也不保存结果
+func (r *Runner) runScan(targets *targetSet, total int, options *types.CmdOptions) error {
 	// 创建进度条
-	bar := output.CreateProgressBar(len(targets))
-
-	// 创建上下文用于控制goroutine
-	doneChan := make(chan struct{}, func() int {
-		caps := r.Config.URLWorkerCount * 2
-		if caps < 1 {
-			caps = 1
-		}
-		if caps > len(targets) {
-			return len(targets)
-		}
-		return caps
-	}())
+	bar := output.CreateProgressBar(total)
 	stopRefreshChan := make(chan struct{})
 
 	// 添加定时刷新进度条的功能
@@ -203,28 +198,13 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptions) error {
 			}
 		}
 	}()
-
-	// 启动进度条更新协程
 	startTime := time.Now()
-	go func() {
-		for range doneChan {
-			if err := bar.Add(1); err != nil {
-				logger.Debug(fmt.Sprintf("更新进度条出错: %v", err))
-			}
-		}
-	}()
-
-	// 收集结果的协程
-	go func() {
-		for data := range resultChan {
-			r.mutex.Lock()
-			r.Results[data.target] = data.result
-			r.mutex.Unlock()
-		}
-	}()
 
 	// 存储输出的结果 - 线程安全的结果输出
+	var printMutex sync.Mutex
 	saveResult := func(msg string) {
+		printMutex.Lock()
+		defer printMutex.Unlock()
 		fmt.Print("\033[2K\r")
 		fmt.Println(msg)
 		if err := bar.RenderBlank(); err != nil {
@@ -239,11 +219,22 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptions) error {
 
 	var urlWg sync.WaitGroup
 
+	// 处理单个URL，端口扫描模式下先扫描端口并识别协议
+	process := ProcessURL
+	if options.Ports != "" {
+		process = ProcessPort
+	}
+
 	// 创建URL处理工作池（通过统一封装）
 	pool, err := NewWorkPoolWithFunc(
 		r.Config.URLWorkerCount,
 		func(i interface{}) {
 			defer urlWg.Done()
+			defer func() {
+				if err := bar.Add(1); err != nil {
+					logger.Debug(fmt.Sprintf("更新进度条出错: %v", err))
+				}
+			}()
 			task, ok := i.(urlTask)
 			if !ok {
 				logger.Error("无效的URL任务类型")
@@ -251,44 +242,25 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptions) error {
 			}
 
 			target := task.target
-
-			// 处理单个URL
-			targetResult, err := ProcessURL(target, options.Proxy, options.Timeout, r.Config.FingerWorkerCount)
+			targetResult, err := process(target, options.Proxy, options.Timeout, r.Config.FingerWorkerCount)
+			if err == nil && targetResult == nil {
+				// 端口未开放，不输出结果
+				r.Stats.Closed.Add(1)
+				return
+			}
 			if err != nil {
 				logger.Error(fmt.Sprintf("处理目标 %s 失败: %v", target, err))
+				addr, vhost := common.SplitVhost(target)
 				targetResult = &TargetResult{
-					URL:     target,
+					URL:     addr,
+					VHost:   vhost,
 					Matches: make([]*FingerMatch, 0),
 				}
 			}
 
-			// 将结果写入文件并显示结果
+			// 将结果写入文件并显示结果，输出后不再持有结果
 			handleMatchResults(targetResult, options, saveResult, r.Config.OutputFormat)
-
-			// 结果已输出，释放大对象以降低常驻内存
-			for _, m := range targetResult.Matches {
-				m.Request = nil
-				m.Response = nil
-			}
-			targetResult.LastRequest = nil
-			targetResult.LastResponse = nil
-
-			// 通过通道发送结果
-			select {
-			case resultChan <- struct {
-				target string
-				result *TargetResult
-			}{target, targetResult}:
-			default:
-				logger.Debug("结果通道已满，丢弃结果")
-			}
-
-			// 通知完成一个任务
-			select {
-			case doneChan <- struct{}{}:
-			default:
-				logger.Debug("完成通道已满")
-			}
+			r.Stats.record(targetResult)
 		},
 		r.Config.URLWorkerCount*5,
 		3*time.Minute,
@@ -300,21 +272,25 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptions) error {
 	}
 	defer pool.Release()
 
-	// 提交所有目标到线程池
-	for _, target := range targets {
+	// 逐个生成目标并提交到线程池，线程池已满时阻塞等待
+	walkErr := targets.each(func(target string) bool {
 		urlWg.Add(1)
 		if err := pool.Invoke(urlTask{target: target}); err != nil {
 			urlWg.Done()
 			logger.Error(fmt.Sprintf("提交目标 %s 到线程池失败: %v", target, err))
+			r.Stats.Failed.Add(1)
 		}
+		return true
+	})
+	if walkErr != nil {
+		logger.Error(walkErr.Error())
+	}
+	if targets.duplicates > 0 {
+		logger.Info(fmt.Sprintf("跳过重复目标：%v个", targets.duplicates))
 	}
-
-	// 等待当前批次完成
-	urlWg.Wait()
 
 	// 等待所有URL处理完成
-	close(resultChan)
-	close(doneChan)
+	urlWg.Wait()
 
 	// 停止刷新进度条
 	close(stopRefreshChan)
@@ -325,17 +301,116 @@ func (r *Runner) runScan(targets []string, options *types.CmdOptions) error {
 	}
 
 	// 显示扫描耗时信息
+	scanned := r.Stats.Total()
 	elapsedTime := time.Since(startTime)
-	itemsPerSecond := float64(len(targets)) / elapsedTime.Seconds()
+	itemsPerSecond := float64(scanned) / elapsedTime.Seconds()
 
 	maxProgress := fmt.Sprintf("指纹识别 100%% [==================================================] (%d/%d, %.2f it/s)",
-		len(targets), len(targets), itemsPerSecond)
+		scanned, scanned, itemsPerSecond)
 	fmt.Println(maxProgress)
 
 	// 打印池统计信息
 	stats := GetRulePoolStats()
 	logger.Info(fmt.Sprintf("规则池统计 - 总任务: %d, 已完成: %d, 失败: %d",
 		stats.TotalTasks, stats.CompletedTasks, stats.FailedTasks))
+	scheme := network.GetSchemeCacheStats()
+	logger.Info(fmt.Sprintf("协议探测缓存 - 命中: %d, 未命中: %d", scheme.Hits, scheme.Misses))
+
+	return walkErr
+}
 
+// applySettings 将HTTP默认配置与连接设置、请求头、代理池、速率限制、缓存与内存阈值应用到对应的全局设置，值为0时保持内置默认值
+func applySettings(options *types.CmdOptions) error {
+	if options.Http.MaxBody > 0 {
+		network.MaxDefaultBody = options.Http.MaxBody
+	}
+	if options.Http.MaxRedirects > 0 {
+		network.MaxRedirects = options.Http.MaxRedirects
+	}
+	var retryDelay time.Duration
+	if options.RetryDelay != "" {
+		d, err := time.ParseDuration(options.RetryDelay)
+		if err != nil {
+			return fmt.Errorf("重试退避时间格式错误: %v", err)
+		}
+		retryDelay = d
+	}
+	network.SetRetryPolicy(options.Retries, retryDelay)
+	network.EnableH2C = options.Http.H2C
+	network.DisableHTTP2 = options.Http.DisableHTTP2
+	network.DisableKeepAlives = options.Http.DisableKeepAlive
+	if err := network.SetHeaderProfile(options.Http.HeaderProfile); err != nil {
+		return err
+	}
+	if err := network.SetGlobalHeaders(options.Http.Headers, options.Http.Cookie); err != nil {
+		return err
+	}
+	network.DisableSpoof = options.Http.NoSpoof
+	if err := network.SetTLSFingerprint(options.Http.TLSFingerprint); err != nil {
+		return err
+	}
+	if options.ProxyStrategy != "" {
+		network.ProxyStrategy = options.ProxyStrategy
+	}
+	if err := network.InitProxyPool(options.Proxy, network.ProxyStrategy); err != nil {
+		return err
+	}
+	if err := network.SetHostMappings(options.DNS.Resolve); err != nil {
+		return err
+	}
+	if err := network.SetResolvers(options.DNS.Resolvers, time.Duration(options.DNS.Timeout)*time.Second); err != nil {
+		return err
+	}
+	network.SetRateLimit(network.RateLimitOptions{
+		HostConcurrency: options.HostConcurrency,
+		HostRate:        float64(options.HostRate),
+		GlobalRate:      float64(options.RateLimit),
+	})
+	var ttl time.Duration
+	if options.Cache.TTL != "" {
+		d, err := time.ParseDuration(options.Cache.TTL)
+		if err != nil {
+			return fmt.Errorf("缓存有效期格式错误: %v", err)
+		}
+		ttl = d
+	}
+	if options.Cache.Size > 0 || ttl > 0 {
+		SetCacheOptions(options.Cache.Size, ttl)
+	}
+	if options.Memory.High > 0 || options.Memory.Critical > 0 {
+		high, critical := globalMonitor.highMemThreshold, globalMonitor.criticalMemThreshold
+		if options.Memory.High > 0 {
+			high = options.Memory.High * 1024 * 1024
+		}
+		if options.Memory.Critical > 0 {
+			critical = options.Memory.Critical * 1024 * 1024
+		}
+		SetMemoryThresholds(high, critical)
+	}
 	return nil
 }
+
+// initReverse 根据反连配置文件与 -oob-* 参数初始化反连平台，命令行指定本地监听地址时覆盖配置文件使用内置反连服务
+func initReverse(options *types.CmdOptions) error {
+	conf := &options.Reverse
+	if options.ReverseConfig != "" {
+		loaded, err := reverse.LoadConfig(options.ReverseConfig)
+		if err != nil {
+			return err
+		}
+		conf = loaded
+	}
+	if options.OOBDns != "" || options.OOBHttp != "" || options.OOBLdap != "" {
+		conf.Provider = reverse.ProviderLocal
+		conf.Local.DnsAddr = options.OOBDns
+		conf.Local.HttpAddr = options.OOBHttp
+		conf.Local.LdapAddr = options.OOBLdap
+		if options.OOBDomain != "" {
+			conf.Local.Domain = options.OOBDomain
+		}
+		if options.OOBIP != "" {
+			conf.Local.IP = options.OOBIP
+		}
+	}
+	return reverse.Init(conf)
+}
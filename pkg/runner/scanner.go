@@ -1,73 +1,59 @@
 package runner
 
 import (
-	"bufio"
 	"fmt"
+	"gxx/pkg/finger"
+	"gxx/pkg/network"
 	"gxx/types"
 	"gxx/utils/common"
 	"gxx/utils/logger"
 	"gxx/utils/output"
-	"os"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// getTargets 从命令行参数或文件中读取目标，并进行去重处理
-func getTargets(options *types.CmdOptions) []string {
-	// 优先使用命令行直接指定的目标
-	if len(options.Target) > 0 {
-		originalCount := len(options.Target)
-		targets := common.RemoveDuplicateURLs(options.Target)
-		duplicateCount := originalCount - len(targets)
-		logger.Info(fmt.Sprintf("原始目标数量：%v个，重复目标数量：%v个，去重后目标数量：%v个", originalCount, duplicateCount, len(targets)))
-		return targets
-	}
-
-	// 其次从文件读取（流式扫描，内存占用更低）
-	if options.TargetsFile == "" {
-		return nil
+// getTargets 返回命令行参数、目标文件或标准输入中的目标，目标文件与标准输入在扫描时逐行读取，
+// CIDR、IP段、端口范围与 @文件 引用在遍历时才按端口列表与虚拟主机列表展开，并跳过排除列表中的目标
+func getTargets(options *types.CmdOptions) (*targetSet, error) {
+	targets := &targetSet{vhosts: common.RemoveDuplicateURLs(options.VHosts)}
+	if options.Ports != "" {
+		ports, err := network.ParsePorts(options.Ports)
+		if err != nil {
+			return nil, fmt.Errorf("解析端口列表失败: %v", err)
+		}
+		targets.ports = ports
+		logger.Info(fmt.Sprintf("端口扫描端口数量：%v个", len(ports)))
 	}
-
-	file, err := os.Open(options.TargetsFile)
+	exclude, err := newTargetFilter(options.ExcludeTargets)
 	if err != nil {
-		logger.Error(fmt.Sprintf("读取目标文件失败: %v", err))
-		return nil
+		return nil, err
 	}
-	defer func() { _ = file.Close() }()
-
-	scanner := bufio.NewScanner(file)
-	// 提升扫描缓存，避免异常长行导致的扫描失败
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	unique := make(map[string]struct{}, 1024)
-	totalLines := 0
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		totalLines++
-		if _, ok := unique[line]; !ok {
-			unique[line] = struct{}{}
-		}
+	targets.exclude = exclude
+	if options.DedupSize > 0 {
+		targets.dedup = newBloomFilter(options.DedupSize, dedupFPRate)
 	}
-	if err := scanner.Err(); err != nil {
-		logger.Error(fmt.Sprintf("扫描目标文件出错: %v", err))
+	if len(targets.vhosts) > 0 {
+		logger.Info(fmt.Sprintf("虚拟主机数量：%v个", len(targets.vhosts)))
 	}
 
-	targets := make([]string, 0, len(unique))
-	for t := range unique {
-		targets = append(targets, t)
+	// 优先使用命令行直接指定的目标，其次从文件或标准输入逐行读取
+	switch {
+	case len(options.Target) > 0:
+		originalCount := len(options.Target)
+		targets.lines = common.RemoveDuplicateURLs(options.Target)
+		duplicateCount := originalCount - len(targets.lines)
+		logger.Info(fmt.Sprintf("原始目标数量：%v个，重复目标数量：%v个，去重后目标数量：%v个", originalCount, duplicateCount, len(targets.lines)))
+	case options.TargetsFile != "":
+		targets.file = options.TargetsFile
+	case common.StdinPiped():
+		targets.file = "-"
+		logger.Info("从标准输入读取目标")
 	}
-	duplicateCount := totalLines - len(targets)
-	logger.Info(fmt.Sprintf("原始目标数量：%v个，重复目标数量：%v个，去重后目标数量：%v个", totalLines, duplicateCount, len(targets)))
-
-	return targets
+	return targets, nil
 }
 
-// ProcessURL 处理单个URL的所有指纹识别，获取目标基础信息并执行指纹识别
+// ProcessURL 处理单个URL的所有指纹识别，获取目标基础信息并执行指纹识别，target 可携带虚拟主机
 func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult, error) {
 	// 确保目标不为空
 	if target == "" {
@@ -75,8 +61,10 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 	}
 
 	// 创建目标结果对象，提前预分配
+	addr, vhost := common.SplitVhost(target)
 	targetResult := &TargetResult{
-		URL:        target,
+		URL:        addr,
+		VHost:      vhost,
 		StatusCode: 0,
 		Title:      "",
 		Server:     types.EmptyServerInfo(),
@@ -84,12 +72,15 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 		Wappalyzer: nil,
 	}
 
-	// 获取目标基础信息
+	// 获取目标基础信息，基础请求与指纹规则请求的失败记录到同一错误统计
 	baseInfoResp, err := GetBaseInfo(target, proxy, timeout)
+	errs := baseInfoResp.Errors
+	defer targetResult.setErrors(errs)
 
-	// 即使获取基础信息失败，也继续处理
+	// 即使获取基础信息失败，也继续处理，非HTTP服务仍然执行 tcp/udp 指纹
 	if err != nil {
 		logger.Debug(fmt.Sprintf("获取目标 %s 基础信息失败: %v", target, err))
+		targetResult.Matches = runFingerDetection(addr, vhost, &BaseInfo{Server: targetResult.Server, Errors: errs}, proxy, timeout, isServiceFinger)
 		return targetResult, nil
 	}
 
@@ -115,13 +106,14 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 	targetResult.LastRequest = lastRequest
 	targetResult.LastResponse = lastResponse
 
-	UpdateTargetCache(variableMap, targetResult.URL, false)
+	UpdateTargetCache(variableMap, vhostCacheTarget(targetResult.URL, vhost), false)
 
 	// 创建基础信息对象
 	baseInfo := &BaseInfo{
 		Title:      targetResult.Title,
 		Server:     targetResult.Server,
 		StatusCode: targetResult.StatusCode,
+		Errors:     errs,
 	}
 
 	// 如果没有指纹规则，直接返回结果
@@ -130,17 +122,25 @@ func ProcessURL(target string, proxy string, timeout int, _ int) (*TargetResult,
 	}
 
 	// 执行指纹识别
-	matches := runFingerDetection(baseInfoResp.Url, baseInfo, proxy, timeout)
+	matches := runFingerDetection(baseInfoResp.Url, vhost, baseInfo, proxy, timeout, nil)
 	targetResult.Matches = matches
 
 	// 指纹规则运行完成之后立即删除缓存，减少内存压力
-	ClearTargetURLCache(targetResult.URL)
+	ClearTargetURLCache(vhostCacheTarget(targetResult.URL, vhost))
 
 	return targetResult, nil
 }
 
-// runFingerDetection 执行指纹识别，使用全局规则池高效处理指纹识别任务
-func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout int) []*FingerMatch {
+// setErrors 将目标的请求失败记录写入结果
+func (r *TargetResult) setErrors(errs *network.ErrorStats) {
+	r.ErrorCount = errs.Total()
+	r.Errors = errs.Counts()
+	r.FinalError = errs.Final()
+}
+
+// runFingerDetection 执行指纹识别，使用全局规则池高效处理指纹识别任务，vhost 为目标的虚拟主机，
+// filter 不为空时只执行其返回 true 的指纹
+func runFingerDetection(target, vhost string, baseInfo *BaseInfo, proxy string, timeout int, filter func(*finger.Finger) bool) []*FingerMatch {
 	// 确保全局规则池已初始化
 	if !IsRulePoolInitialized() {
 		logger.Error("全局规则池未初始化")
@@ -155,7 +155,19 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 
 	// 复制快照，避免并发安全隐患
 	localFingers := GetAllFingerSnapshot()
+	if filter != nil {
+		filtered := localFingers[:0]
+		for _, fg := range localFingers {
+			if filter(fg) {
+				filtered = append(filtered, fg)
+			}
+		}
+		localFingers = filtered
+	}
 	ruleCount = len(localFingers)
+	if ruleCount == 0 {
+		return []*FingerMatch{}
+	}
 
 	// 结果通道容量限制，避免为大规模规则集分配过大的缓冲
 	chanCap := ruleCount
@@ -173,15 +185,29 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 	// 记录开始时间用于性能监控
 	startTime := time.Now()
 
+	// 先启动结果收集协程，规则任务阻塞发送结果时不会因通道已满而卡住（仅由单协程写入，无需互斥）
+	matches := make([]*FingerMatch, 0, ruleCount/4+1)
+	resultDone := make(chan struct{})
+
+	go func() {
+		defer close(resultDone)
+		for result := range resultChan {
+			if result != nil && result.Result {
+				matches = append(matches, result)
+			}
+		}
+	}()
+
 	// 统计实际提交的任务数
 	submittedTasks := int64(0)
 
-	// 提交所有指纹任务到全局规则池
+	// 提交所有指纹任务到全局规则池，规则池已满时阻塞等待
 	for _, fingerprint := range localFingers {
 		wg.Add(1)
 
 		task := &RuleTask{
 			Target:     target,
+			VHost:      vhost,
 			Finger:     fingerprint,
 			BaseInfo:   baseInfo,
 			Proxy:      proxy,
@@ -190,15 +216,9 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 			WaitGroup:  &wg,
 		}
 
-		// 简化重试机制，只在池满时重试一次
-		submitErr := SubmitRuleTask(task)
-		if submitErr != nil {
-			time.Sleep(1 * time.Millisecond)
-			submitErr = SubmitRuleTask(task)
-		}
-
-		if submitErr != nil {
-			logger.Debug(fmt.Sprintf("提交指纹任务失败: %s, 错误: %v", fingerprint.Id, submitErr))
+		if err := SubmitRuleTask(task); err != nil {
+			logger.Error(fmt.Sprintf("提交指纹任务失败: %s, 错误: %v", fingerprint.Id, err))
+			atomic.AddInt64(&rulePoolStats.FailedTasks, 1)
 			wg.Done()
 			continue
 		}
@@ -206,19 +226,6 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 		submittedTasks++
 	}
 
-	// 启动结果收集协程，避免阻塞主流程（仅由单协程写入，无需互斥）
-	matches := make([]*FingerMatch, 0, ruleCount/4+1)
-	resultDone := make(chan struct{})
-
-	go func() {
-		defer close(resultDone)
-		for result := range resultChan {
-			if result != nil && result.Result {
-				matches = append(matches, result)
-			}
-		}
-	}()
-
 	// 等待所有指纹任务完成
 	wg.Wait()
 	close(resultChan)
@@ -234,15 +241,24 @@ func runFingerDetection(target string, baseInfo *BaseInfo, proxy string, timeout
 	return matches
 }
 
+// isServiceFinger 判断是否为只包含 tcp/udp 等非HTTP规则的服务指纹，目标不是HTTP服务时只执行此类指纹
+func isServiceFinger(fg *finger.Finger) bool {
+	return len(fg.Rules) > 0 && !fg.IsHTTPType()
+}
+
 // handleMatchResults 处理匹配结果，将结果输出到终端和文件
 func handleMatchResults(targetResult *TargetResult, options *types.CmdOptions, printResult func(string), outputFormat string) {
 	output.HandleMatchResults(&output.TargetResult{
 		URL:        targetResult.URL,
+		VHost:      targetResult.VHost,
 		StatusCode: targetResult.StatusCode,
 		Title:      targetResult.Title,
 		ServerInfo: targetResult.Server,
 		Matches:    convertFingerMatches(targetResult.Matches),
 		Wappalyzer: targetResult.Wappalyzer,
+		ErrorCount: targetResult.ErrorCount,
+		Errors:     targetResult.Errors,
+		FinalError: targetResult.FinalError,
 	}, options.Output, options.SockOutput, printResult, outputFormat, targetResult.LastResponse)
 }
 
@@ -251,28 +267,34 @@ func convertFingerMatches(matches []*FingerMatch) []*output.FingerMatch {
 	result := make([]*output.FingerMatch, len(matches))
 	for i, match := range matches {
 		result[i] = &output.FingerMatch{
-			Finger:   match.Finger,
-			Result:   match.Result,
-			Request:  match.Request,
-			Response: match.Response,
+			Finger:    match.Finger,
+			Result:    match.Result,
+			Request:   match.Request,
+			Response:  match.Response,
+			Extracted: match.Extracted,
 		}
 	}
 	return result
 }
 
+// record 累计一个目标的识别结果
+func (s *ScanStats) record(result *TargetResult) {
+	if len(result.Matches) > 0 {
+		s.Matched.Add(1)
+	} else {
+		s.Unmatched.Add(1)
+	}
+}
+
+// Total 返回已处理的目标总数
+func (s *ScanStats) Total() int64 {
+	return s.Matched.Load() + s.Unmatched.Load() + s.Closed.Load() + s.Failed.Load()
+}
+
 // printSummary 打印汇总信息
-func printSummary(targets []string, results map[string]*TargetResult) {
-	// 将pkg.TargetResult映射转换为output.TargetResult映射
-	outputResults := make(map[string]*output.TargetResult)
-	for key, result := range results {
-		outputResults[key] = &output.TargetResult{
-			URL:        result.URL,
-			StatusCode: result.StatusCode,
-			Title:      result.Title,
-			ServerInfo: result.Server,
-			Matches:    convertFingerMatches(result.Matches),
-			Wappalyzer: result.Wappalyzer,
-		}
+func printSummary(stats *ScanStats) {
+	output.PrintSummary(int(stats.Total()), int(stats.Matched.Load()), int(stats.Unmatched.Load()))
+	if closed := stats.Closed.Load(); closed > 0 {
+		logger.Info(fmt.Sprintf("未开放端口：%v个", closed))
 	}
-	output.PrintSummary(targets, outputResults)
 }
@@ -0,0 +1,382 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"gxx/pkg/network"
+	"gxx/utils/common"
+	"gxx/utils/logger"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxRangeSize 单条IP段目标最多包含的地址数量，避免误输入过大的IPv6网段
+const maxRangeSize = 1 << 24
+
+// targetSpec 一条目标输入，CIDR、IP段与端口范围在遍历时按需生成目标，不预先展开
+type targetSpec struct {
+	raw   string // 原样使用的目标，如URL
+	name  string // 主机名，IP段目标为空
+	start net.IP // IP段起始地址
+	end   net.IP // IP段结束地址
+	ports []int  // 端口列表，为空时目标不带端口
+	vhost string // 目标携带的虚拟主机
+}
+
+// targetSet 扫描目标集合，目标文件与标准输入逐行读取，CIDR、IP段、端口与虚拟主机列表在遍历时才展开，
+// 展开后的目标经布隆过滤器去重，并跳过排除的目标
+type targetSet struct {
+	lines      []string      // 命令行指定的目标
+	file       string        // 目标文件，"-" 表示标准输入
+	ports      []int         // -ports 端口列表
+	vhosts     []string      // 虚拟主机列表，对未携带虚拟主机的目标逐一展开
+	exclude    *targetFilter // 排除的目标
+	dedup      *bloomFilter  // 展开后目标的去重过滤器，为空时不去重
+	duplicates int           // 遍历时跳过的重复目标数量
+}
+
+// each 按输入顺序遍历全部目标，fn 返回 false 时停止遍历；无效的目标输入与跳过的重复目标记录日志，读取文件失败时返回错误
+func (s *targetSet) each(fn func(target string) bool) error {
+	_, err := s.walk(fn, false)
+	return err
+}
+
+// count 遍历一次目标并返回去重后的数量，与 each 一样跳过无效的目标输入（日志由 each 记录）；标准输入只能读取一次，返回-1
+func (s *targetSet) count() (int, error) {
+	if s.file == "-" {
+		return -1, nil
+	}
+	total := 0
+	if _, err := s.walk(func(string) bool { total++; return true }, true); err != nil {
+		return 0, err
+	}
+	if s.duplicates > 0 {
+		logger.Info(fmt.Sprintf("展开后目标数量：%v个，重复目标数量：%v个", total, s.duplicates))
+	}
+	if s.dedup != nil {
+		s.dedup.reset()
+	}
+	s.duplicates = 0
+	return total, nil
+}
+
+// walk 遍历命令行目标与目标文件，无效的目标输入跳过，quiet 为 true 时不记录无效输入与重复目标的日志
+func (s *targetSet) walk(fn func(target string) bool, quiet bool) (bool, error) {
+	visited := make(map[string]struct{})
+	for _, line := range s.lines {
+		if ok, err := s.walkLine(line, visited, fn, quiet); !ok || err != nil {
+			return ok, err
+		}
+	}
+	if s.file != "" {
+		return s.walkFile(s.file, visited, fn, quiet)
+	}
+	return true, nil
+}
+
+// walkFile 逐行读取目标文件，"-" 表示标准输入
+func (s *targetSet) walkFile(fileName string, visited map[string]struct{}, fn func(target string) bool, quiet bool) (bool, error) {
+	var reader io.Reader = os.Stdin
+	if fileName != "-" {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return false, fmt.Errorf("读取目标文件失败: %v", err)
+		}
+		defer func() { _ = file.Close() }()
+		reader = file
+	}
+
+	scanner := bufio.NewScanner(reader)
+	// 提升扫描缓存，避免异常长行导致的扫描失败
+	buf := make([]byte, 0, 1024*1024)
+	scanner.Buffer(buf, 1024*1024)
+	for scanner.Scan() {
+		if ok, err := s.walkLine(scanner.Text(), visited, fn, quiet); !ok || err != nil {
+			return ok, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("扫描目标文件出错: %v", err)
+	}
+	return true, nil
+}
+
+// walkLine 解析一条目标输入并遍历其展开的目标，"@文件" 引用的文件逐行作为目标输入（可嵌套引用，同一文件只读取一次）
+func (s *targetSet) walkLine(line string, visited map[string]struct{}, fn func(target string) bool, quiet bool) (bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true, nil
+	}
+	if strings.HasPrefix(line, "@") {
+		fileName := strings.TrimPrefix(line, "@")
+		if _, ok := visited[fileName]; ok {
+			return true, nil
+		}
+		visited[fileName] = struct{}{}
+		return s.walkFile(fileName, visited, fn, quiet)
+	}
+
+	spec, err := parseTargetSpec(line, s.ports)
+	if err != nil {
+		if !quiet {
+			logger.Error(err.Error())
+		}
+		return true, nil
+	}
+	return spec.each(s.exclude, func(addr string) bool {
+		if spec.vhost != "" || len(s.vhosts) == 0 {
+			return s.emit(common.JoinVhost(addr, spec.vhost), fn, quiet)
+		}
+		for _, vhost := range s.vhosts {
+			if !s.emit(common.JoinVhost(addr, vhost), fn, quiet) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+// emit 跳过重复目标后交给 fn 处理；布隆过滤器存在误判，每个跳过的目标都记录日志，便于找回误判跳过的目标
+func (s *targetSet) emit(target string, fn func(target string) bool, quiet bool) bool {
+	if s.dedup != nil && s.dedup.add(target) {
+		s.duplicates++
+		if !quiet {
+			logger.Info(fmt.Sprintf("跳过重复目标：%s", target))
+		}
+		return true
+	}
+	return fn(target)
+}
+
+// each 遍历该输入生成的全部地址，fn 返回 false 时停止遍历并返回 false
+func (s *targetSpec) each(exclude *targetFilter, fn func(addr string) bool) bool {
+	if s.raw != "" {
+		if exclude.excludedTarget(s.raw) {
+			return true
+		}
+		return fn(s.raw)
+	}
+	if s.start == nil {
+		if exclude.excludedHost(s.name) {
+			return true
+		}
+		return s.eachPort(s.name, fn)
+	}
+	for ip := s.start; ip != nil; ip = nextIP(ip) {
+		if !exclude.excludedIP(ip) && !s.eachPort(ip.String(), fn) {
+			return false
+		}
+		if ip.Equal(s.end) {
+			break
+		}
+	}
+	return true
+}
+
+// eachPort 按端口列表生成 host:port，端口列表为空时只生成主机
+func (s *targetSpec) eachPort(host string, fn func(addr string) bool) bool {
+	if len(s.ports) == 0 {
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		return fn(host)
+	}
+	for _, port := range s.ports {
+		if !fn(net.JoinHostPort(host, strconv.Itoa(port))) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTargetSpec 解析一条目标输入，支持URL、主机名、IP、CIDR、IP段（如 10.0.0.1-10.0.0.50、10.0.0.1-50）
+// 与端口范围（如 host:8000-8010），未指定端口时使用 ports（-ports 端口列表）；URL与无法解析为端口的输入原样使用
+func parseTargetSpec(line string, ports []int) (*targetSpec, error) {
+	addr, vhost := common.SplitVhost(line)
+	spec := &targetSpec{vhost: vhost, ports: ports}
+	if strings.Contains(addr, "://") {
+		spec.raw = addr
+		return spec, nil
+	}
+
+	host, portSpec := splitHostPorts(addr)
+	if portSpec != "" {
+		parsed, err := network.ParsePorts(portSpec)
+		if err != nil {
+			spec.raw = addr
+			return spec, nil
+		}
+		spec.ports = parsed
+	}
+
+	start, end, err := parseIPRange(host)
+	if err != nil {
+		return nil, fmt.Errorf("目标 %q 无效: %v", line, err)
+	}
+	if start == nil {
+		if len(spec.ports) == 0 || strings.Contains(host, "/") {
+			spec.raw = addr
+			return spec, nil
+		}
+		spec.name = host
+		return spec, nil
+	}
+
+	size := new(big.Int).Sub(new(big.Int).SetBytes(end), new(big.Int).SetBytes(start))
+	if size.Cmp(big.NewInt(maxRangeSize-1)) > 0 {
+		return nil, fmt.Errorf("目标 %q 包含的地址超过 %d 个", line, maxRangeSize)
+	}
+	spec.start, spec.end = start, end
+	return spec, nil
+}
+
+// splitHostPorts 拆分主机与端口部分，IPv6地址携带端口时需使用 [addr]:port 格式
+func splitHostPorts(addr string) (string, string) {
+	if strings.HasPrefix(addr, "[") {
+		if i := strings.Index(addr, "]"); i > 0 {
+			return addr[1:i], strings.TrimPrefix(addr[i+1:], ":")
+		}
+	}
+	if strings.Count(addr, ":") == 1 {
+		i := strings.LastIndex(addr, ":")
+		return addr[:i], addr[i+1:]
+	}
+	return addr, ""
+}
+
+// parseIPRange 解析IP、CIDR与IP段，返回起止地址，host 不是IP时返回 nil
+func parseIPRange(host string) (net.IP, net.IP, error) {
+	if prefix, _, ok := strings.Cut(host, "/"); ok {
+		if net.ParseIP(prefix) == nil {
+			return nil, nil, nil
+		}
+		_, ipNet, err := net.ParseCIDR(host)
+		if err != nil {
+			return nil, nil, err
+		}
+		start := normalizeIP(ipNet.IP)
+		end := make(net.IP, len(start))
+		for i := range start {
+			end[i] = start[i] | ^ipNet.Mask[len(ipNet.Mask)-len(start)+i]
+		}
+		return start, end, nil
+	}
+	if first, last, ok := strings.Cut(host, "-"); ok {
+		start := normalizeIP(net.ParseIP(first))
+		if start == nil {
+			return nil, nil, nil
+		}
+		end := normalizeIP(net.ParseIP(last))
+		if end == nil {
+			// 简写的IPv4段，如 10.0.0.1-50
+			octet, err := strconv.Atoi(last)
+			if err != nil || len(start) != net.IPv4len || octet < 0 || octet > 255 {
+				return nil, nil, fmt.Errorf("IP段结束地址 %q 无效", last)
+			}
+			end = make(net.IP, net.IPv4len)
+			copy(end, start)
+			end[3] = byte(octet)
+		}
+		if len(end) != len(start) || bytes.Compare(start, end) > 0 {
+			return nil, nil, fmt.Errorf("IP段 %q 的起止地址无效", host)
+		}
+		return start, end, nil
+	}
+	if ip := normalizeIP(net.ParseIP(host)); ip != nil {
+		return ip, ip, nil
+	}
+	return nil, nil, nil
+}
+
+// normalizeIP IPv4地址统一为4字节表示，便于比较与遍历
+func normalizeIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip
+}
+
+// nextIP 返回下一个IP，到达地址空间末尾时返回 nil
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next
+		}
+	}
+	return nil
+}
+
+// targetFilter 排除的目标，支持IP、CIDR、IP段与主机名，按主机匹配，不区分端口
+type targetFilter struct {
+	ranges [][2]net.IP
+	hosts  map[string]struct{}
+}
+
+// newTargetFilter 解析排除列表，列表为空时返回 nil
+func newTargetFilter(entries []string) (*targetFilter, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	f := &targetFilter{hosts: make(map[string]struct{})}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host := strings.Trim(network.HostKey(entry), "[]")
+		start, end, err := parseIPRange(host)
+		if err != nil {
+			return nil, fmt.Errorf("排除目标 %q 无效: %v", entry, err)
+		}
+		if start == nil {
+			f.hosts[host] = struct{}{}
+			continue
+		}
+		f.ranges = append(f.ranges, [2]net.IP{start, end})
+	}
+	return f, nil
+}
+
+// excludedIP 判断IP是否在排除列表中
+func (f *targetFilter) excludedIP(ip net.IP) bool {
+	if f == nil {
+		return false
+	}
+	for _, r := range f.ranges {
+		if len(ip) == len(r[0]) && bytes.Compare(ip, r[0]) >= 0 && bytes.Compare(ip, r[1]) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedHost 判断主机名或IP是否在排除列表中
+func (f *targetFilter) excludedHost(host string) bool {
+	if f == nil {
+		return false
+	}
+	if ip := normalizeIP(net.ParseIP(host)); ip != nil {
+		return f.excludedIP(ip)
+	}
+	_, ok := f.hosts[strings.ToLower(host)]
+	return ok
+}
+
+// excludedTarget 判断URL等原样使用的目标的主机是否在排除列表中
+func (f *targetFilter) excludedTarget(target string) bool {
+	if f == nil {
+		return false
+	}
+	if !strings.Contains(target, "://") {
+		target, _, _ = strings.Cut(target, "/")
+	}
+	return f.excludedHost(strings.Trim(network.HostKey(target), "[]"))
+}
@@ -6,15 +6,18 @@ package runner
 
 import (
 	"gxx/pkg/finger"
+	"gxx/pkg/network"
 	"gxx/pkg/wappalyzer"
 	"gxx/types"
 	"gxx/utils/proto"
 	"net/http"
+	"sync/atomic"
 )
 
 // BaseInfoResponse 包含目标基础信息和HTTP响应
 type BaseInfoResponse struct {
 	Url        string
+	VHost      string // 虚拟主机，为空时使用目标地址
 	Title      string
 	Server     *types.ServerInfo
 	StatusCode int32
@@ -22,11 +25,20 @@ type BaseInfoResponse struct {
 	Wappalyzer *wappalyzer.TypeWappalyzer
 	// BodyBytes 保存已读取的响应体字节，便于后续复用，避免重复读取与拷贝
 	BodyBytes []byte
+	// Truncated 响应体是否超过读取上限被截断
+	Truncated bool
+	// Timing 首页请求的各阶段耗时
+	Timing *network.Timing
+	// ConnInfo 首页请求的连接地址信息
+	ConnInfo *network.ConnInfo
+	// Errors 目标的请求失败记录，指纹规则的请求继续记录到其中
+	Errors *network.ErrorStats
 }
 
 // TargetResult 存储每个目标的扫描结果
 type TargetResult struct {
 	URL          string                     // 目标地址
+	VHost        string                     // 虚拟主机，为空时使用目标地址
 	StatusCode   int32                      // 状态码
 	Title        string                     // 站点标题
 	Server       *types.ServerInfo          // server信息
@@ -34,14 +46,18 @@ type TargetResult struct {
 	Wappalyzer   *wappalyzer.TypeWappalyzer // 站点信息数据
 	LastRequest  *proto.Request             // 该URL的请求缓存
 	LastResponse *proto.Response            // 该URL的响应缓存
+	ErrorCount   int                        // 请求失败次数（含重试）
+	Errors       map[string]int             // 按错误分类（dns、refused、reset、timeout、tls、5xx、other）统计的失败次数
+	FinalError   string                     // 重试耗尽后仍失败的最后一个请求的错误分类，请求均成功时为空
 }
 
 // FingerMatch 存储每个匹配的指纹信息
 type FingerMatch struct {
-	Finger   *finger.Finger  // 指纹信息
-	Result   bool            // 识别结果
-	Request  *proto.Request  // 请求数据
-	Response *proto.Response // 响应数据
+	Finger    *finger.Finger    // 指纹信息
+	Result    bool              // 识别结果
+	Request   *proto.Request    // 请求数据
+	Response  *proto.Response   // 响应数据
+	Extracted map[string]string // 规则 output 中提取的字段，如 product、version、cpe
 }
 
 // BaseInfo 存储目标的基础信息
@@ -49,6 +65,7 @@ type BaseInfo struct {
 	Title      string
 	Server     *types.ServerInfo
 	StatusCode int32
+	Errors     *network.ErrorStats // 目标的请求失败记录，为空时不记录
 }
 
 // ScanConfig 存储扫描配置参数
@@ -61,3 +78,11 @@ type ScanConfig struct {
 	OutputFile        string
 	SockOutputFile    string
 }
+
+// ScanStats 扫描结果的增量统计，结果输出后只保留计数
+type ScanStats struct {
+	Matched   atomic.Int64 // 匹配到指纹的目标数
+	Unmatched atomic.Int64 // 未匹配到指纹的目标数
+	Closed    atomic.Int64 // 端口扫描中未开放的端口数
+	Failed    atomic.Int64 // 提交到线程池失败的目标数
+}
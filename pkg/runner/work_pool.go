@@ -68,6 +68,7 @@ var (
 // RuleTask 规则处理任务结构（供调用方构造任务使用）
 type RuleTask struct {
 	Target     string
+	VHost      string // 虚拟主机，为空时使用目标地址
 	Finger     *finger.Finger
 	BaseInfo   *BaseInfo
 	Proxy      string
@@ -93,10 +94,11 @@ func InitGlobalRulePool(workerCount int) error {
 		atomic.AddInt64(&rulePoolStats.CompletedTasks, 1)
 	}
 
+	// 不限制阻塞等待的提交数，规则池已满时 SubmitRuleTask 阻塞等待而不是返回错误
 	pool, err := NewWorkPoolWithFunc(
 		workerCount,
 		handler,
-		workerCount*10,
+		0,
 		2*time.Minute,
 		func(i interface{}) {
 			atomic.AddInt64(&rulePoolStats.FailedTasks, 1)
@@ -123,7 +125,7 @@ func ReleaseRulePool() {
 // IsRulePoolInitialized 是否已初始化全局规则池
 func IsRulePoolInitialized() bool { return globalRulePool != nil }
 
-// SubmitRuleTask 提交规则任务到全局规则池
+// SubmitRuleTask 提交规则任务到全局规则池，规则池已满时阻塞等待空闲的工作线程，仅在规则池未初始化或已释放时返回错误
 func SubmitRuleTask(task *RuleTask) error {
 	if globalRulePool == nil {
 		return fmt.Errorf("全局规则池未初始化")
@@ -166,6 +168,7 @@ func processRuleTask(task *RuleTask) {
 	result, err := evaluateFingerprintWithCache(
 		task.Finger,
 		task.Target,
+		task.VHost,
 		task.BaseInfo,
 		task.Proxy,
 		task.Timeout,
@@ -177,12 +180,8 @@ func processRuleTask(task *RuleTask) {
 		return
 	}
 
-	// 只有匹配成功的结果才发送到结果通道
+	// 只有匹配成功的结果才发送到结果通道，结果收集协程在提交任务前已启动，阻塞发送不会丢弃结果
 	if result != nil && result.Result {
-		select {
-		case task.ResultChan <- result:
-		default:
-			logger.Debug(fmt.Sprintf("结果通道已满，丢弃规则 %s 的结果", task.Finger.Id))
-		}
+		task.ResultChan <- result
 	}
 }
@@ -8,28 +8,89 @@
 package types
 
 import (
+	"gxx/pkg/reverse"
+
 	"github.com/projectdiscovery/goflags"
 )
 
 // YamlFingerType 指纹文件类型
 type YamlFingerType struct {
-	PocFile string // POC文件路径
-	PocYaml string // 单个POC yaml文件
+	PocFile       string `yaml:"pf" toml:"pf"`                         // POC文件路径
+	PocYaml       string `yaml:"p" toml:"p"`                           // 单个POC yaml文件
+	NmapProbes    string `yaml:"nmap-probes" toml:"nmap-probes"`       // nmap-service-probes 文件，转换为指纹一并加载
+	NmapIntensity int    `yaml:"nmap-intensity" toml:"nmap-intensity"` // nmap服务探针的探测强度（0-9），只加载 rarity 不大于该值的探针
+	NmapConvert   string `yaml:"-" toml:"-"`                           // 将 nmap-service-probes 转换为指纹yaml文件保存到该目录后退出
+}
+
+// HttpOptions HTTP请求默认配置，为0时使用内置默认值
+type HttpOptions struct {
+	MaxBody          int64               `yaml:"max-body" toml:"max-body"`                   // 响应体读取上限（字节，按解压后计算），对应 -max-body
+	MaxRedirects     int                 `yaml:"max-redirects" toml:"max-redirects"`         // 最大重定向次数
+	H2C              bool                `yaml:"h2c" toml:"h2c"`                             // 探测HTTP目标是否支持h2c
+	DisableHTTP2     bool                `yaml:"disable-http2" toml:"disable-http2"`         // 禁用HTTPS目标的HTTP/2协商
+	DisableKeepAlive bool                `yaml:"disable-keepalive" toml:"disable-keepalive"` // 禁用连接复用
+	Headers          goflags.StringSlice `yaml:"header" toml:"header"`                       // 全局请求头，格式 "Name: value"
+	Cookie           string              `yaml:"cookie" toml:"cookie"`                       // 全局Cookie
+	HeaderProfile    string              `yaml:"header-profile" toml:"header-profile"`       // 请求头预设：default、chrome、curl、none
+	NoSpoof          bool                `yaml:"no-spoof" toml:"no-spoof"`                   // 禁用随机X-Forwarded-For与随机Cookie
+	TLSFingerprint   string              `yaml:"tls-fingerprint" toml:"tls-fingerprint"`     // TLS客户端指纹：go、chrome、firefox、safari、randomized
+}
+
+// DNSOptions 域名解析配置，均为空时使用系统解析
+type DNSOptions struct {
+	Resolve   goflags.StringSlice `yaml:"resolve" toml:"resolve"`                   // 静态主机映射，格式 host:ip
+	Resolvers goflags.StringSlice `yaml:"resolver" toml:"resolver"`                 // 自定义DNS服务器列表
+	Timeout   int                 `yaml:"resolver-timeout" toml:"resolver-timeout"` // 单个DNS服务器的查询超时（秒）
+}
+
+// CacheOptions 请求缓存配置，为0或空时使用内置默认值
+type CacheOptions struct {
+	Size int    `yaml:"size" toml:"size"` // 最大缓存条目数
+	TTL  string `yaml:"ttl" toml:"ttl"`   // 缓存有效期，如 10m、1h
+}
+
+// MemoryOptions 内存监控阈值，单位MB，为0时使用内置默认值
+type MemoryOptions struct {
+	High     uint64 `yaml:"high" toml:"high"`         // 高内存使用阈值
+	Critical uint64 `yaml:"critical" toml:"critical"` // 临界内存使用阈值
 }
 
-// CmdOptions 命令行选项结构体
+// CmdOptions 命令行选项结构体，配置文件中的键名与命令行参数名一致
 type CmdOptions struct {
-	Target      goflags.StringSlice // 测试目标
-	TargetsFile string              // 测试目标文件
-	Threads     int                 // 并发线程数
-	Output      string              // 输出文件路径
-	PocOptions  YamlFingerType      // POC yaml文件配置
-	Timeout     int                 // 超时时间，默认5秒
-	Retries     int                 // 重试次数，默认3次
-	Proxy       string              // 代理地址
-	Debug       bool                // 设置debug模式
-	NoFileLog   bool                // 是否禁用文件日志，仅输出到控制台
-	JSONOutput  bool                // 是否使用JSON格式输出结果
-	SockOutput  string              // socket文件输出路径，启用后会以JSON格式输出到socket文件
-	RuleThreads int                 // 指纹规则线程数
+	Target          goflags.StringSlice `yaml:"url" toml:"url"`                           // 测试目标
+	TargetsFile     string              `yaml:"file" toml:"file"`                         // 测试目标文件
+	VHosts          goflags.StringSlice `yaml:"vhosts" toml:"vhosts"`                     // 虚拟主机列表，对每个未携带虚拟主机的目标逐一识别
+	ExcludeTargets  goflags.StringSlice `yaml:"exclude-targets" toml:"exclude-targets"`   // 排除的目标，支持IP、CIDR、IP段与主机名
+	DedupSize       int                 `yaml:"dedup-size" toml:"dedup-size"`             // 目标去重布隆过滤器的预计目标数量，0为不去重
+	Ports           string              `yaml:"ports" toml:"ports"`                       // 端口扫描的端口列表，设置后对主机与CIDR目标先扫描端口再识别协议
+	Threads         int                 `yaml:"threads" toml:"threads"`                   // 并发线程数
+	Output          string              `yaml:"output" toml:"output"`                     // 输出文件路径
+	PocOptions      YamlFingerType      `yaml:"poc" toml:"poc"`                           // POC yaml文件配置
+	Timeout         int                 `yaml:"timeout" toml:"timeout"`                   // 超时时间，默认5秒
+	Retries         int                 `yaml:"retries" toml:"retries"`                   // 请求的最大尝试次数（含首次请求），1为不重试
+	RetryDelay      string              `yaml:"retry-delay" toml:"retry-delay"`           // 首次重试的退避时间，之后按指数增长并加入随机抖动
+	Proxy           string              `yaml:"proxy" toml:"proxy"`                       // 代理地址，支持逗号分隔的代理列表或代理文件
+	ProxyStrategy   string              `yaml:"proxy-strategy" toml:"proxy-strategy"`     // 代理选择策略：round-robin、random、sticky
+	Debug           bool                `yaml:"debug" toml:"debug"`                       // 设置debug模式
+	NoFileLog       bool                `yaml:"no-file-log" toml:"no-file-log"`           // 是否禁用文件日志，仅输出到控制台
+	JSONOutput      bool                `yaml:"json" toml:"json"`                         // 是否使用JSON格式输出结果
+	SockOutput      string              `yaml:"sock" toml:"sock"`                         // socket文件输出路径，启用后会以JSON格式输出到socket文件
+	RuleThreads     int                 `yaml:"rulethreads" toml:"rulethreads"`           // 指纹规则线程数
+	ReverseConfig   string              `yaml:"reverse-config" toml:"reverse-config"`     // 反连平台配置文件
+	OOBDns          string              `yaml:"oob-dns" toml:"oob-dns"`                   // 本地反连DNS监听地址
+	OOBHttp         string              `yaml:"oob-http" toml:"oob-http"`                 // 本地反连HTTP监听地址
+	OOBLdap         string              `yaml:"oob-ldap" toml:"oob-ldap"`                 // 本地反连LDAP监听地址
+	OOBDomain       string              `yaml:"oob-domain" toml:"oob-domain"`             // 本地反连根域名
+	OOBIP           string              `yaml:"oob-ip" toml:"oob-ip"`                     // 本地反连对外公布的IP
+	RateLimit       int                 `yaml:"rate-limit" toml:"rate-limit"`             // 全局每秒请求数，0为不限制
+	HostRate        int                 `yaml:"host-rate" toml:"host-rate"`               // 单个主机每秒请求数，0为不限制
+	HostConcurrency int                 `yaml:"host-concurrency" toml:"host-concurrency"` // 单个主机最大并发请求数，0为不限制
+	Reverse         reverse.Config      `yaml:"reverse" toml:"reverse"`                   // 反连平台配置，-reverse-config 指定的文件优先
+	Http            HttpOptions         `yaml:"http" toml:"http"`                         // HTTP请求默认配置
+	DNS             DNSOptions          `yaml:"dns" toml:"dns"`                           // 域名解析配置
+	Cache           CacheOptions        `yaml:"cache" toml:"cache"`                       // 请求缓存配置
+	Memory          MemoryOptions       `yaml:"memory" toml:"memory"`                     // 内存监控阈值
+	Profile         string              `yaml:"profile" toml:"profile"`                   // 扫描预设：fast、thorough、stealth
+	Config          string              `yaml:"-" toml:"-"`                               // 配置文件路径
+	DumpConfig      bool                `yaml:"-" toml:"-"`                               // 输出合并后的配置并退出
 }
@@ -26,7 +26,9 @@ import (
 	"math/rand"
 	"net"
 	"net/url"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -357,6 +359,26 @@ func ParseTarget(target, path string) string {
 	return target + path
 }
 
+// VhostSeparator 目标与虚拟主机之间的分隔符，如 "https://10.0.0.5:8443 | oa.corp.local"
+const VhostSeparator = "|"
+
+// SplitVhost 拆分携带虚拟主机的目标，返回目标地址与虚拟主机，未携带时虚拟主机为空
+func SplitVhost(target string) (string, string) {
+	addr, vhost, found := strings.Cut(target, VhostSeparator)
+	if !found {
+		return strings.TrimSpace(target), ""
+	}
+	return strings.TrimSpace(addr), strings.TrimSpace(vhost)
+}
+
+// JoinVhost 将目标地址与虚拟主机合并为目标，虚拟主机为空时原样返回目标地址
+func JoinVhost(addr, vhost string) string {
+	if vhost == "" {
+		return addr
+	}
+	return addr + " " + VhostSeparator + " " + vhost
+}
+
 func UrlTypeToString(u *proto.UrlType) string {
 	var buf strings.Builder
 	if u.Scheme != "" {
@@ -557,3 +579,29 @@ func RemoveTrailingSlash(url string) string {
 	}
 	return url
 }
+
+// StdinPiped 判断标准输入是否为管道或重定向的文件
+func StdinPiped() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice == 0
+}
+
+// ParseSize 解析字节数，支持纯数字（字节）与带单位的写法，单位不区分大小写：B、K/KB、M/MB、G/GB，如 512KB、2MB
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, suffix := range []struct {
+		name string
+		size int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(value, suffix.name) {
+			value, unit = strings.TrimSpace(strings.TrimSuffix(value, suffix.name)), suffix.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("无效的大小: %q，应为字节数或带单位的大小，如 512KB、2MB", s)
+	}
+	return n * unit, nil
+}
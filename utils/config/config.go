@@ -1,16 +0,0 @@
-/*
-  - Package config
-    @Author: zhizhuo
-    @IDE：GoLand
-    @File: config.go
-    @Date: 2025/2/20 下午4:09*
-*/
-package config
-
-var (
-	ReverseCeyeApiKey string
-	ReverseCeyeDomain string
-	ReverseJndi       string
-	ReverseLdapPort   string
-	ReverseApiPort    string
-)
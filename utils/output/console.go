@@ -59,23 +59,11 @@ func GetOutputFormat(jsonOutput bool, outputPath string) string {
 }
 
 // PrintSummary 打印汇总信息
-func PrintSummary(targets []string, results map[string]*TargetResult) {
-	matchCount := 0
-	noMatchCount := 0
-
-	// 统计匹配成功和失败的数量
-	for _, targetResult := range results {
-		if len(targetResult.Matches) > 0 {
-			matchCount++
-		} else {
-			noMatchCount++
-		}
-	}
-
+func PrintSummary(total, matchCount, noMatchCount int) {
 	// 输出统计信息
 	fmt.Println(color.CyanString("─────────────────────────────────────────────────────"))
 	fmt.Printf("扫描统计: 目标总数 %d, 匹配成功 %d, 匹配失败 %d\n",
-		len(targets), matchCount, noMatchCount)
+		total, matchCount, noMatchCount)
 }
 
 // HandleMatchResults 处理匹配结果并输出到控制台
@@ -92,8 +80,12 @@ func HandleMatchResults(targetResult *TargetResult, output string, sockOutput st
 	}
 
 	// 构建输出信息
-	baseInfoStr := fmt.Sprintf("URL：%s %s  标题：%s  Server：%s",
-		targetResult.URL, statusCodeStr, targetResult.Title, serverInfo)
+	vhostStr := ""
+	if targetResult.VHost != "" {
+		vhostStr = fmt.Sprintf("  虚拟主机：%s", targetResult.VHost)
+	}
+	baseInfoStr := fmt.Sprintf("URL：%s%s %s  标题：%s  Server：%s",
+		targetResult.URL, vhostStr, statusCodeStr, targetResult.Title, serverInfo)
 
 	// 构建技术栈信息（合并为一行）
 	var techInfoStr string
@@ -136,7 +128,11 @@ func HandleMatchResults(targetResult *TargetResult, output string, sockOutput st
 		// 收集所有匹配的指纹名称
 		fingerNames := make([]string, 0, len(targetResult.Matches))
 		for _, match := range targetResult.Matches {
-			fingerNames = append(fingerNames, match.Finger.Info.Name)
+			name := match.Finger.Info.Name
+			if version := match.Extracted["version"]; version != "" {
+				name += " " + version
+			}
+			fingerNames = append(fingerNames, name)
 		}
 		matchResultStr = fmt.Sprintf("  指纹：[%s]  匹配结果：%s%s%s",
 			strings.Join(fingerNames, "，"), successColor, "成功", resetColor)
@@ -170,8 +166,15 @@ func CreateWriteOptions(targetResult *TargetResult, outputPath string, format st
 	// 收集指纹信息
 	var IsMatch bool
 	fingerList := make([]*finger.Finger, 0, len(targetResult.Matches))
+	var extracted map[string]map[string]string
 	for _, match := range targetResult.Matches {
 		fingerList = append(fingerList, match.Finger)
+		if len(match.Extracted) > 0 {
+			if extracted == nil {
+				extracted = make(map[string]map[string]string)
+			}
+			extracted[match.Finger.Id] = match.Extracted
+		}
 	}
 	if len(targetResult.Matches) > 0 {
 		IsMatch = true
@@ -182,12 +185,17 @@ func CreateWriteOptions(targetResult *TargetResult, outputPath string, format st
 		Output:      outputPath,
 		Format:      format,
 		Target:      targetResult.URL,
+		VHost:       targetResult.VHost,
 		Fingers:     fingerList,
 		StatusCode:  targetResult.StatusCode,
 		Title:       targetResult.Title,
 		ServerInfo:  targetResult.ServerInfo,
 		Wappalyzer:  targetResult.Wappalyzer,
 		FinalResult: IsMatch,
+		Extracted:   extracted,
+		ErrorCount:  targetResult.ErrorCount,
+		Errors:      targetResult.Errors,
+		FinalError:  targetResult.FinalError,
 	}
 
 	// 检查并设置响应头信息
@@ -34,7 +34,7 @@ func WriteHeader(format string) error {
 		if err := csvWriter.Write([]string{
 			"URL", "状态码", "标题", "服务器信息",
 			"Web服务器", "JS框架", "JS库", "Web框架", "编程语言",
-			"指纹ID", "指纹名称", "响应头", "匹配结果", "备注",
+			"指纹ID", "指纹名称", "响应头", "匹配结果", "备注", "虚拟主机",
 		}); err != nil {
 			return fmt.Errorf("写入CSV表头失败: %v", err)
 		}
@@ -226,6 +226,7 @@ func WriteFingerprints(opts *WriteOptions) error {
 		// 构建JSON对象
 		jsonOutput := &JSONOutput{
 			URL:         opts.Target,
+			VHost:       opts.VHost,
 			StatusCode:  opts.StatusCode,
 			Title:       opts.Title,
 			Server:      serverInfoStr,
@@ -235,6 +236,12 @@ func WriteFingerprints(opts *WriteOptions) error {
 			Wappalyzer:  opts.Wappalyzer,
 			MatchResult: opts.FinalResult,
 			Remark:      remark,
+			IP:          opts.Response.GetConn().GetDestination().GetIp(),
+			Timing:      opts.Response.GetTiming(),
+			Extracted:   opts.Extracted,
+			ErrorCount:  opts.ErrorCount,
+			Errors:      opts.Errors,
+			FinalError:  opts.FinalError,
 		}
 
 		// 序列化为JSON
@@ -267,6 +274,7 @@ func WriteFingerprints(opts *WriteOptions) error {
 			strings.ReplaceAll(headersStr, "\n", "\\n"), // CSV中换行符需要转义
 			fmt.Sprintf("%v", opts.FinalResult),
 			remark,
+			opts.VHost,
 		}); err != nil {
 			return fmt.Errorf("写入CSV记录失败: %v", err)
 		}
@@ -279,6 +287,10 @@ func WriteFingerprints(opts *WriteOptions) error {
 
 		sb.WriteString("URL: ")
 		sb.WriteString(opts.Target)
+		if opts.VHost != "" {
+			sb.WriteString("\n虚拟主机: ")
+			sb.WriteString(opts.VHost)
+		}
 		sb.WriteString("\n状态码: ")
 		sb.WriteString(fmt.Sprintf("%d", opts.StatusCode))
 		sb.WriteString("\n标题: ")
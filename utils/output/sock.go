@@ -133,6 +133,7 @@ func WriteToSock(opts *WriteOptions) error {
 	// 构建JSON对象
 	jsonOutput := &JSONOutput{
 		URL:         opts.Target,
+		VHost:       opts.VHost,
 		StatusCode:  opts.StatusCode,
 		Title:       opts.Title,
 		Server:      serverInfoStr,
@@ -142,6 +143,12 @@ func WriteToSock(opts *WriteOptions) error {
 		Wappalyzer:  opts.Wappalyzer,
 		MatchResult: opts.FinalResult,
 		Remark:      remark,
+		IP:          opts.Response.GetConn().GetDestination().GetIp(),
+		Timing:      opts.Response.GetTiming(),
+		Extracted:   opts.Extracted,
+		ErrorCount:  opts.ErrorCount,
+		Errors:      opts.Errors,
+		FinalError:  opts.FinalError,
 	}
 
 	// 序列化为JSON
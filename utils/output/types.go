@@ -24,49 +24,66 @@ var (
 
 // WriteOptions 定义写入选项结构体，用于传递写入参数
 type WriteOptions struct {
-	Output      string                     // 输出文件路径
-	Format      string                     // 输出格式(csv/txt/json)
-	Target      string                     // 目标URL
-	Fingers     []*finger.Finger           // 指纹列表
-	StatusCode  int32                      // 状态码
-	Title       string                     // 页面标题
-	ServerInfo  *types.ServerInfo          // 服务器信息
-	RespHeaders string                     // 响应头
-	Response    *proto.Response            // 完整响应对象(可选)
-	Wappalyzer  *wappalyzer.TypeWappalyzer // 站点使用技术
-	FinalResult bool                       // 最终匹配结果
-	Remark      string                     // 备注(可选)
+	Output      string                       // 输出文件路径
+	Format      string                       // 输出格式(csv/txt/json)
+	Target      string                       // 目标URL
+	VHost       string                       // 虚拟主机
+	Fingers     []*finger.Finger             // 指纹列表
+	StatusCode  int32                        // 状态码
+	Title       string                       // 页面标题
+	ServerInfo  *types.ServerInfo            // 服务器信息
+	RespHeaders string                       // 响应头
+	Response    *proto.Response              // 完整响应对象(可选)
+	Wappalyzer  *wappalyzer.TypeWappalyzer   // 站点使用技术
+	FinalResult bool                         // 最终匹配结果
+	Remark      string                       // 备注(可选)
+	Extracted   map[string]map[string]string // 指纹ID → 提取的字段，如 product、version、cpe
+	ErrorCount  int                          // 请求失败次数（含重试）
+	Errors      map[string]int               // 按错误分类统计的失败次数
+	FinalError  string                       // 重试耗尽后仍失败的最后一个请求的错误分类
 }
 
 // JSONOutput JSON格式输出结构体
 type JSONOutput struct {
-	URL         string                     `json:"url"`
-	StatusCode  int32                      `json:"status_code"`
-	Title       string                     `json:"title"`
-	Server      string                     `json:"server"`
-	FingerIDs   []string                   `json:"finger_ids,omitempty"`
-	FingerNames []string                   `json:"finger_names,omitempty"`
-	Headers     string                     `json:"headers,omitempty"`
-	Wappalyzer  *wappalyzer.TypeWappalyzer `json:"wappalyzer,omitempty"`
-	MatchResult bool                       `json:"match_result"`
-	Remark      string                     `json:"remark,omitempty"`
+	URL         string                       `json:"url"`
+	VHost       string                       `json:"vhost,omitempty"` // 虚拟主机
+	StatusCode  int32                        `json:"status_code"`
+	Title       string                       `json:"title"`
+	Server      string                       `json:"server"`
+	FingerIDs   []string                     `json:"finger_ids,omitempty"`
+	FingerNames []string                     `json:"finger_names,omitempty"`
+	Headers     string                       `json:"headers,omitempty"`
+	Wappalyzer  *wappalyzer.TypeWappalyzer   `json:"wappalyzer,omitempty"`
+	MatchResult bool                         `json:"match_result"`
+	Remark      string                       `json:"remark,omitempty"`
+	IP          string                       `json:"ip,omitempty"`          // 首页请求实际连接的IP
+	Timing      *proto.TimingType            `json:"timing,omitempty"`      // 首页请求各阶段耗时（毫秒）
+	Extracted   map[string]map[string]string `json:"extracted,omitempty"`   // 指纹ID → 提取的字段，如 product、version、cpe
+	ErrorCount  int                          `json:"error_count,omitempty"` // 请求失败次数（含重试）
+	Errors      map[string]int               `json:"errors,omitempty"`      // 按错误分类统计的失败次数
+	FinalError  string                       `json:"final_error,omitempty"` // 重试耗尽后仍失败的最后一个请求的错误分类
 }
 
 // TargetResult 存储每个目标的扫描结果
 type TargetResult struct {
 	URL        string                     // 目标地址
+	VHost      string                     // 虚拟主机
 	StatusCode int32                      // 状态码
 	Title      string                     // 站点标题
 	ServerInfo *types.ServerInfo          // server信息
 	Fingers    []*finger.Finger           // 匹配的指纹列表
 	Matches    []*FingerMatch             // 匹配详细信息
 	Wappalyzer *wappalyzer.TypeWappalyzer // 站点信息数据
+	ErrorCount int                        // 请求失败次数（含重试）
+	Errors     map[string]int             // 按错误分类统计的失败次数
+	FinalError string                     // 重试耗尽后仍失败的最后一个请求的错误分类
 }
 
 // FingerMatch 存储每个匹配的指纹信息
 type FingerMatch struct {
-	Finger   *finger.Finger  // 指纹信息
-	Result   bool            // 识别结果
-	Request  *proto.Request  // 请求数据
-	Response *proto.Response // 响应数据
+	Finger    *finger.Finger    // 指纹信息
+	Result    bool              // 识别结果
+	Request   *proto.Request    // 请求数据
+	Response  *proto.Response   // 响应数据
+	Extracted map[string]string // 规则 output 中提取的字段，如 product、version、cpe
 }